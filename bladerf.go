@@ -13,6 +13,8 @@ import (
 	"fmt"
 	exception "github.com/erayarslan/go-bladerf/error"
 	"github.com/mattn/go-pointer"
+	"strconv"
+	"strings"
 	"unsafe"
 )
 
@@ -20,6 +22,37 @@ func GetError(code C.int) error {
 	return exception.New(int(code))
 }
 
+type hertz uint64
+
+func (value hertz) String() string {
+	text := strconv.FormatFloat(float64(value), 'g', -1, 64)
+	return strings.Replace(strings.Replace(text, "e+0", "e", 1), "e+", "e", 1)
+}
+
+func formatArguments(arguments ...interface{}) string {
+	pairs := make([]string, 0, len(arguments)/2)
+
+	for i := 0; i+1 < len(arguments); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%v=%v", arguments[i], arguments[i+1]))
+	}
+
+	return strings.Join(pairs, " ")
+}
+
+func operationError(code C.int, operation string, arguments ...interface{}) error {
+	if code == 0 {
+		return nil
+	}
+
+	return exception.NewWithOperation(int(code), operation, formatArguments(arguments...))
+}
+
+// codeError reports a failure detected on the Go side in the same form as
+// operationError.
+func codeError(code exception.Code, operation string, arguments ...interface{}) error {
+	return exception.NewWithOperation(int(code), operation, formatArguments(arguments...))
+}
+
 //export StreamCallback
 func StreamCallback(
 	dev *C.struct_bladerf,
@@ -29,27 +62,51 @@ func StreamCallback(
 	numSamples C.size_t,
 	userDataPtr unsafe.Pointer,
 ) unsafe.Pointer {
-	userData := pointer.Restore(userDataPtr).(UserData)
+	userData := pointer.Restore(userDataPtr).(*UserData)
 
-	for i := uint32(0); i < uint32(numSamples); i++ {
-		userData.results[i] = int16(
-			*((*C.int16_t)(unsafe.Pointer(uintptr(samples) + (C.sizeof_int16_t * uintptr(i))))),
-		)
+	if userData.direction == Tx {
+		var status GoStream
+		buffer := userData.buffers[userData.next]
+
+		if userData.callback8 != nil {
+			status = userData.callback8(userData.buffer8(buffer))
+		} else {
+			status = userData.callback(userData.buffer(buffer))
+		}
+
+		if status == GoStreamNoData {
+			return StreamNoData
+		} else if status == GoStreamShutdown {
+			return StreamShutdown
+		}
+
+		return userData.nextBuffer()
 	}
 
-	defer C.free(samples)
-	status := userData.callback(userData.results)
+	var status GoStream
+
+	if userData.rxCallback != nil {
+		var rxMetadata Metadata
+
+		if metadata != nil {
+			rxMetadata = LoadMetadata(metadata)
+		}
+
+		status = userData.rxCallback(userData.buffer(samples), rxMetadata)
+	} else if userData.callback8 != nil {
+		copy(userData.results8, userData.buffer8(samples))
+		status = userData.callback8(userData.results8)
+	} else {
+		copy(userData.results, userData.buffer(samples))
+		status = userData.callback(userData.results)
+	}
 
 	if status == GoStreamNoData {
 		return StreamNoData
 	} else if status == GoStreamShutdown {
 		return StreamShutdown
 	} else {
-		return unsafe.Pointer(
-			(*C.uint16_t)(
-				C.malloc((C.size_t)(C.sizeof_uint16_t * uintptr(numSamples) * 2 * 1)),
-			),
-		)
+		return userData.nextBuffer()
 	}
 }
 
@@ -66,12 +123,66 @@ func (version *Version) Print() {
 func (bladeRF *BladeRF) LoadFpga(imagePath string) error {
 	path := C.CString(imagePath)
 	defer C.free(unsafe.Pointer(path))
-	return GetError(C.bladerf_load_fpga(bladeRF.ref, path))
+	return operationError(C.bladerf_load_fpga(bladeRF.ref, path), "load_fpga", "path", imagePath)
+}
+
+func (bladeRF *BladeRF) FlashFirmware(imagePath string) error {
+	path := C.CString(imagePath)
+	defer C.free(unsafe.Pointer(path))
+	return operationError(C.bladerf_flash_firmware(bladeRF.ref, path), "flash_firmware", "path", imagePath)
+}
+
+func (bladeRF *BladeRF) FlashFpga(imagePath string) error {
+	path := C.CString(imagePath)
+	defer C.free(unsafe.Pointer(path))
+	return operationError(C.bladerf_flash_fpga(bladeRF.ref, path), "flash_fpga", "path", imagePath)
+}
+
+func (bladeRF *BladeRF) EraseStoredFpga() error {
+	return operationError(C.bladerf_erase_stored_fpga(bladeRF.ref), "erase_stored_fpga")
+}
+
+// DeviceReset restarts the board. The handle must be closed afterwards and
+// the device opened again once it has re-enumerated.
+func (bladeRF *BladeRF) DeviceReset() error {
+	return operationError(C.bladerf_device_reset(bladeRF.ref), "device_reset")
+}
+
+// JumpToBootloader clears the firmware and restarts the board into the FX3
+// bootloader, where it is only visible through GetBootloaderList.
+func (bladeRF *BladeRF) JumpToBootloader() error {
+	return operationError(C.bladerf_jump_to_bootloader(bladeRF.ref), "jump_to_bootloader")
+}
+
+func LoadFirmwareFromBootloader(deviceIdentifier string, backend Backend, bus uint8, addr uint8, imagePath string) error {
+	var identifier *C.char
+
+	if deviceIdentifier != "" {
+		identifier = C.CString(deviceIdentifier)
+		defer C.free(unsafe.Pointer(identifier))
+	}
+
+	path := C.CString(imagePath)
+	defer C.free(unsafe.Pointer(path))
+
+	return operationError(C.bladerf_load_fw_from_bootloader(
+		identifier,
+		C.bladerf_backend(backend),
+		C.uint8_t(bus),
+		C.uint8_t(addr),
+		path,
+	), "load_fw_from_bootloader", "bus", bus, "addr", addr, "path", imagePath)
+}
+
+// LoadFirmware boots a device listed by GetBootloaderList with the firmware
+// image at imagePath. The firmware is loaded to RAM only.
+func (deviceInfo *DeviceInfo) LoadFirmware(imagePath string) error {
+	return LoadFirmwareFromBootloader("", deviceInfo.Backend, uint8(deviceInfo.UsbBus), uint8(deviceInfo.UsbAddr), imagePath)
 }
 
 func (bladeRF *BladeRF) GetFpgaSize() (FpgaSize, error) {
 	var size C.bladerf_fpga_size
-	err := GetError(C.bladerf_get_fpga_size(bladeRF.ref, &size))
+	err := operationError(C.bladerf_get_fpga_size(bladeRF.ref, &size), "get_fpga_size")
 
 	if err != nil {
 		return 0, err
@@ -83,7 +194,7 @@ func (bladeRF *BladeRF) GetFpgaSize() (FpgaSize, error) {
 func (bladeRF *BladeRF) GetQuickTune(channel Channel) (QuickTune, error) {
 	var quickTune C.struct_bladerf_quick_tune
 
-	err := GetError(C.bladerf_get_quick_tune(bladeRF.ref, C.bladerf_channel(channel), &quickTune))
+	err := operationError(C.bladerf_get_quick_tune(bladeRF.ref, C.bladerf_channel(channel), &quickTune), "get_quick_tune", "ch", channel)
 
 	if err != nil {
 		return QuickTune{}, err
@@ -93,12 +204,12 @@ func (bladeRF *BladeRF) GetQuickTune(channel Channel) (QuickTune, error) {
 }
 
 func (bladeRF *BladeRF) CancelScheduledReTunes(channel Channel) error {
-	return GetError(C.bladerf_cancel_scheduled_retunes(bladeRF.ref, C.bladerf_channel(channel)))
+	return operationError(C.bladerf_cancel_scheduled_retunes(bladeRF.ref, C.bladerf_channel(channel)), "cancel_scheduled_retunes", "ch", channel)
 }
 
 func (bladeRF *BladeRF) GetFpgaSource() (FpgaSource, error) {
 	var source C.bladerf_fpga_source
-	err := GetError(C.bladerf_get_fpga_source(bladeRF.ref, &source))
+	err := operationError(C.bladerf_get_fpga_source(bladeRF.ref, &source), "get_fpga_source")
 
 	if err != nil {
 		return 0, err
@@ -109,7 +220,7 @@ func (bladeRF *BladeRF) GetFpgaSource() (FpgaSource, error) {
 
 func (bladeRF *BladeRF) GetFpgaBytes() (uint32, error) {
 	var size C.size_t
-	err := GetError(C.bladerf_get_fpga_bytes(bladeRF.ref, &size))
+	err := operationError(C.bladerf_get_fpga_bytes(bladeRF.ref, &size), "get_fpga_bytes")
 
 	if err != nil {
 		return 0, err
@@ -121,7 +232,7 @@ func (bladeRF *BladeRF) GetFpgaBytes() (uint32, error) {
 func (bladeRF *BladeRF) GetFpgaFlashSize() (uint32, bool, error) {
 	var size C.uint32_t
 	var isGuess C.bool
-	err := GetError(C.bladerf_get_flash_size(bladeRF.ref, &size, &isGuess))
+	err := operationError(C.bladerf_get_flash_size(bladeRF.ref, &size, &isGuess), "get_flash_size")
 
 	if err != nil {
 		return 0, false, err
@@ -132,7 +243,7 @@ func (bladeRF *BladeRF) GetFpgaFlashSize() (uint32, bool, error) {
 
 func (bladeRF *BladeRF) GetFirmwareVersion() (Version, error) {
 	var version C.struct_bladerf_version
-	err := GetError(C.bladerf_fw_version(bladeRF.ref, &version))
+	err := operationError(C.bladerf_fw_version(bladeRF.ref, &version), "fw_version")
 
 	if err != nil {
 		return Version{}, err
@@ -145,7 +256,7 @@ func (bladeRF *BladeRF) IsFpgaConfigured() (bool, error) {
 	out := C.bladerf_is_fpga_configured(bladeRF.ref)
 
 	if out < 0 {
-		return false, GetError(out)
+		return false, operationError(out, "is_fpga_configured")
 	}
 
 	return out == 1, nil
@@ -157,7 +268,7 @@ func (bladeRF *BladeRF) GetDeviceSpeed() DeviceSpeed {
 
 func (bladeRF *BladeRF) GetFpgaVersion() (Version, error) {
 	var version C.struct_bladerf_version
-	err := GetError(C.bladerf_fpga_version(bladeRF.ref, &version))
+	err := operationError(C.bladerf_fpga_version(bladeRF.ref, &version), "fpga_version")
 
 	if err != nil {
 		return Version{}, err
@@ -177,7 +288,7 @@ func GetDeviceList() ([]DeviceInfo, error) {
 	codeOrCount := C.bladerf_get_device_list(&deviceInfo)
 
 	if codeOrCount < 0 {
-		return nil, GetError(codeOrCount)
+		return nil, operationError(codeOrCount, "get_device_list")
 	}
 
 	count := int(codeOrCount)
@@ -206,7 +317,7 @@ func GetBootloaderList() ([]DeviceInfo, error) {
 	codeOrCount := C.bladerf_get_bootloader_list(&deviceInfo)
 
 	if codeOrCount < 0 {
-		return nil, GetError(codeOrCount)
+		return nil, operationError(codeOrCount, "get_bootloader_list")
 	}
 
 	count := int(codeOrCount)
@@ -236,7 +347,7 @@ func InitDeviceInfo() DeviceInfo {
 
 func (bladeRF *BladeRF) GetDeviceInfo() (DeviceInfo, error) {
 	var deviceInfo C.struct_bladerf_devinfo
-	err := GetError(C.bladerf_get_devinfo(bladeRF.ref, &deviceInfo))
+	err := operationError(C.bladerf_get_devinfo(bladeRF.ref, &deviceInfo), "get_devinfo")
 
 	if err != nil {
 		return DeviceInfo{}, err
@@ -261,7 +372,7 @@ func GetDeviceInfoFromString(deviceString string) (DeviceInfo, error) {
 	defer C.free(unsafe.Pointer(val))
 
 	var deviceInfo C.struct_bladerf_devinfo
-	err := GetError(C.bladerf_get_devinfo_from_str(val, &deviceInfo))
+	err := operationError(C.bladerf_get_devinfo_from_str(val, &deviceInfo), "get_devinfo_from_str", "devstr", deviceString)
 
 	if err != nil {
 		return DeviceInfo{}, err
@@ -272,35 +383,35 @@ func GetDeviceInfoFromString(deviceString string) (DeviceInfo, error) {
 
 func (deviceInfo *DeviceInfo) Open() (BladeRF, error) {
 	var bladeRF *C.struct_bladerf
-	err := GetError(C.bladerf_open_with_devinfo(&bladeRF, deviceInfo.ref))
+	err := operationError(C.bladerf_open_with_devinfo(&bladeRF, deviceInfo.ref), "open_with_devinfo", "serial", deviceInfo.Serial)
 
 	if err != nil {
 		return BladeRF{}, err
 	}
 
-	return BladeRF{ref: bladeRF}, nil
+	return newBladeRF(bladeRF), nil
 }
 
 func OpenWithDeviceIdentifier(identify string) (BladeRF, error) {
 	var bladeRF *C.struct_bladerf
-	err := GetError(C.bladerf_open(&bladeRF, C.CString(identify)))
+	err := operationError(C.bladerf_open(&bladeRF, C.CString(identify)), "open", "devstr", identify)
 
 	if err != nil {
 		return BladeRF{}, err
 	}
 
-	return BladeRF{ref: bladeRF}, nil
+	return newBladeRF(bladeRF), nil
 }
 
 func Open() (BladeRF, error) {
 	var bladeRF *C.struct_bladerf
-	err := GetError(C.bladerf_open(&bladeRF, nil))
+	err := operationError(C.bladerf_open(&bladeRF, nil), "open")
 
 	if err != nil {
 		return BladeRF{}, err
 	}
 
-	return BladeRF{ref: bladeRF}, nil
+	return newBladeRF(bladeRF), nil
 }
 
 func (bladeRF *BladeRF) Close() {
@@ -308,7 +419,7 @@ func (bladeRF *BladeRF) Close() {
 }
 
 func (bladeRF *BladeRF) SetLoopback(loopback Loopback) error {
-	return GetError(C.bladerf_set_loopback(bladeRF.ref, C.bladerf_loopback(loopback)))
+	return operationError(C.bladerf_set_loopback(bladeRF.ref, C.bladerf_loopback(loopback)), "set_loopback", "lb", loopback)
 }
 
 func (bladeRF *BladeRF) IsLoopbackModeSupported(loopback Loopback) bool {
@@ -317,7 +428,7 @@ func (bladeRF *BladeRF) IsLoopbackModeSupported(loopback Loopback) bool {
 
 func (bladeRF *BladeRF) GetLoopback() (Loopback, error) {
 	var loopback C.bladerf_loopback
-	err := GetError(C.bladerf_get_loopback(bladeRF.ref, &loopback))
+	err := operationError(C.bladerf_get_loopback(bladeRF.ref, &loopback), "get_loopback")
 
 	if err != nil {
 		return 0, err
@@ -332,26 +443,31 @@ func (bladeRF *BladeRF) ScheduleReTune(
 	frequency uint64,
 	quickTune QuickTune,
 ) error {
-	return GetError(C.bladerf_schedule_retune(
+	return operationError(C.bladerf_schedule_retune(
 		bladeRF.ref,
 		C.bladerf_channel(channel),
 		C.bladerf_timestamp(timestamp),
 		C.bladerf_frequency(frequency),
 		quickTune.ref,
-	))
+	), "schedule_retune", "ch", channel, "ts", timestamp, "freq", hertz(frequency))
 }
 
 func (bladeRF *BladeRF) SelectBand(channel Channel, frequency uint64) error {
-	return GetError(C.bladerf_select_band(bladeRF.ref, C.bladerf_channel(channel), C.bladerf_frequency(frequency)))
+	return operationError(C.bladerf_select_band(bladeRF.ref, C.bladerf_channel(channel), C.bladerf_frequency(frequency)), "select_band", "ch", channel, "freq", hertz(frequency))
 }
 
+// SetFrequency tunes channel. With an XB-200 attached, the transverter path
+// is first chosen for frequency by XB200Select; the filter bank is left to
+// XB200SetFilterbank.
 func (bladeRF *BladeRF) SetFrequency(channel Channel, frequency uint64) error {
-	return GetError(C.bladerf_set_frequency(bladeRF.ref, C.bladerf_channel(channel), C.bladerf_frequency(frequency)))
+	return tuneFrequency(bladeRF, bladeRF.expansionBoard, bladeRF.correctionTable, channel, frequency, func() error {
+		return operationError(C.bladerf_set_frequency(bladeRF.ref, C.bladerf_channel(channel), C.bladerf_frequency(frequency)), "set_frequency", "ch", channel, "freq", hertz(frequency))
+	})
 }
 
 func (bladeRF *BladeRF) GetFrequency(channel Channel) (uint64, error) {
 	var frequency C.uint64_t
-	err := GetError(C.bladerf_get_frequency(bladeRF.ref, C.bladerf_channel(channel), &frequency))
+	err := operationError(C.bladerf_get_frequency(bladeRF.ref, C.bladerf_channel(channel), &frequency), "get_frequency", "ch", channel)
 
 	if err != nil {
 		return 0, err
@@ -362,11 +478,11 @@ func (bladeRF *BladeRF) GetFrequency(channel Channel) (uint64, error) {
 
 func (bladeRF *BladeRF) SetSampleRate(channel Channel, sampleRate uint) (uint, error) {
 	var actual C.uint
-	err := GetError(C.bladerf_set_sample_rate(
+	err := operationError(C.bladerf_set_sample_rate(
 		bladeRF.ref,
 		C.bladerf_channel(channel),
 		C.bladerf_sample_rate(sampleRate),
-		&actual),
+		&actual), "set_sample_rate", "ch", channel, "rate", hertz(sampleRate),
 	)
 
 	if err != nil {
@@ -377,12 +493,12 @@ func (bladeRF *BladeRF) SetSampleRate(channel Channel, sampleRate uint) (uint, e
 }
 
 func (bladeRF *BladeRF) SetRxMux(mux RxMux) error {
-	return GetError(C.bladerf_set_rx_mux(bladeRF.ref, C.bladerf_rx_mux(mux)))
+	return operationError(C.bladerf_set_rx_mux(bladeRF.ref, C.bladerf_rx_mux(mux)), "set_rx_mux", "mux", mux)
 }
 
 func (bladeRF *BladeRF) GetRxMux() (RxMux, error) {
 	var rxMux C.bladerf_rx_mux
-	err := GetError(C.bladerf_get_rx_mux(bladeRF.ref, &rxMux))
+	err := operationError(C.bladerf_get_rx_mux(bladeRF.ref, &rxMux), "get_rx_mux")
 
 	if err != nil {
 		return 0, err
@@ -398,11 +514,11 @@ func (bladeRF *BladeRF) SetRationalSampleRate(channel Channel, rationalRate Rati
 		integer: C.uint64_t(rationalRate.Integer),
 		den:     C.uint64_t(rationalRate.Den),
 	}
-	err := GetError(C.bladerf_set_rational_sample_rate(
+	err := operationError(C.bladerf_set_rational_sample_rate(
 		bladeRF.ref,
 		C.bladerf_channel(channel),
 		&rationalSampleRate,
-		&actual),
+		&actual), "set_rational_sample_rate", "ch", channel, "rate", hertz(rationalRate.Integer),
 	)
 
 	if err != nil {
@@ -414,7 +530,7 @@ func (bladeRF *BladeRF) SetRationalSampleRate(channel Channel, rationalRate Rati
 
 func (bladeRF *BladeRF) GetSampleRate(channel Channel) (uint, error) {
 	var sampleRate C.uint
-	err := GetError(C.bladerf_get_sample_rate(bladeRF.ref, C.bladerf_channel(channel), &sampleRate))
+	err := operationError(C.bladerf_get_sample_rate(bladeRF.ref, C.bladerf_channel(channel), &sampleRate), "get_sample_rate", "ch", channel)
 
 	if err != nil {
 		return 0, err
@@ -425,7 +541,7 @@ func (bladeRF *BladeRF) GetSampleRate(channel Channel) (uint, error) {
 
 func (bladeRF *BladeRF) GetRationalSampleRate(channel Channel) (RationalRate, error) {
 	var rate C.struct_bladerf_rational_rate
-	err := GetError(C.bladerf_get_rational_sample_rate(bladeRF.ref, C.bladerf_channel(channel), &rate))
+	err := operationError(C.bladerf_get_rational_sample_rate(bladeRF.ref, C.bladerf_channel(channel), &rate), "get_rational_sample_rate", "ch", channel)
 
 	if err != nil {
 		return RationalRate{}, err
@@ -436,7 +552,7 @@ func (bladeRF *BladeRF) GetRationalSampleRate(channel Channel) (RationalRate, er
 
 func (bladeRF *BladeRF) GetSampleRateRange(channel Channel) (Range, error) {
 	var _range *C.struct_bladerf_range
-	err := GetError(C.bladerf_get_sample_rate_range(bladeRF.ref, C.bladerf_channel(channel), &_range))
+	err := operationError(C.bladerf_get_sample_rate_range(bladeRF.ref, C.bladerf_channel(channel), &_range), "get_sample_rate_range", "ch", channel)
 
 	if err != nil {
 		return Range{}, err
@@ -447,7 +563,7 @@ func (bladeRF *BladeRF) GetSampleRateRange(channel Channel) (Range, error) {
 
 func (bladeRF *BladeRF) GetFrequencyRange(channel Channel) (Range, error) {
 	var _range *C.struct_bladerf_range
-	err := GetError(C.bladerf_get_frequency_range(bladeRF.ref, C.bladerf_channel(channel), &_range))
+	err := operationError(C.bladerf_get_frequency_range(bladeRF.ref, C.bladerf_channel(channel), &_range), "get_frequency_range", "ch", channel)
 
 	if err != nil {
 		return Range{}, err
@@ -458,11 +574,11 @@ func (bladeRF *BladeRF) GetFrequencyRange(channel Channel) (Range, error) {
 
 func (bladeRF *BladeRF) SetBandwidth(channel Channel, bandwidth uint) (uint, error) {
 	var actual C.bladerf_bandwidth
-	err := GetError(C.bladerf_set_bandwidth(
+	err := operationError(C.bladerf_set_bandwidth(
 		bladeRF.ref,
 		C.bladerf_channel(channel),
 		C.bladerf_bandwidth(bandwidth),
-		&actual),
+		&actual), "set_bandwidth", "ch", channel, "bw", hertz(bandwidth),
 	)
 
 	if err != nil {
@@ -474,7 +590,7 @@ func (bladeRF *BladeRF) SetBandwidth(channel Channel, bandwidth uint) (uint, err
 
 func (bladeRF *BladeRF) GetBandwidth(channel Channel) (uint, error) {
 	var bandwidth C.bladerf_bandwidth
-	err := GetError(C.bladerf_get_bandwidth(bladeRF.ref, C.bladerf_channel(channel), &bandwidth))
+	err := operationError(C.bladerf_get_bandwidth(bladeRF.ref, C.bladerf_channel(channel), &bandwidth), "get_bandwidth", "ch", channel)
 
 	if err != nil {
 		return 0, err
@@ -485,7 +601,7 @@ func (bladeRF *BladeRF) GetBandwidth(channel Channel) (uint, error) {
 
 func (bladeRF *BladeRF) GetBandwidthRange(channel Channel) (Range, error) {
 	var bfRange *C.struct_bladerf_range
-	err := GetError(C.bladerf_get_bandwidth_range(bladeRF.ref, C.bladerf_channel(channel), &bfRange))
+	err := operationError(C.bladerf_get_bandwidth_range(bladeRF.ref, C.bladerf_channel(channel), &bfRange), "get_bandwidth_range", "ch", channel)
 
 	if err != nil {
 		return Range{}, err
@@ -495,12 +611,12 @@ func (bladeRF *BladeRF) GetBandwidthRange(channel Channel) (Range, error) {
 }
 
 func (bladeRF *BladeRF) SetGain(channel Channel, gain int) error {
-	return GetError(C.bladerf_set_gain(bladeRF.ref, C.bladerf_channel(channel), C.bladerf_gain(gain)))
+	return operationError(C.bladerf_set_gain(bladeRF.ref, C.bladerf_channel(channel), C.bladerf_gain(gain)), "set_gain", "ch", channel, "gain", gain)
 }
 
 func (bladeRF *BladeRF) GetGain(channel Channel) (int, error) {
 	var gain C.bladerf_gain
-	err := GetError(C.bladerf_get_gain(bladeRF.ref, C.bladerf_channel(channel), &gain))
+	err := operationError(C.bladerf_get_gain(bladeRF.ref, C.bladerf_channel(channel), &gain), "get_gain", "ch", channel)
 
 	if err != nil {
 		return 0, err
@@ -514,7 +630,7 @@ func (bladeRF *BladeRF) GetGainStage(channel Channel, stage string) (int, error)
 	defer C.free(unsafe.Pointer(val))
 
 	var gain C.bladerf_gain
-	err := GetError(C.bladerf_get_gain_stage(bladeRF.ref, C.bladerf_channel(channel), val, &gain))
+	err := operationError(C.bladerf_get_gain_stage(bladeRF.ref, C.bladerf_channel(channel), val, &gain), "get_gain_stage", "ch", channel, "stage", stage)
 
 	if err != nil {
 		return 0, err
@@ -526,7 +642,7 @@ func (bladeRF *BladeRF) GetGainStage(channel Channel, stage string) (int, error)
 func (bladeRF *BladeRF) GetGainMode(channel Channel) (GainMode, error) {
 	var mode C.bladerf_gain_mode
 
-	err := GetError(C.bladerf_get_gain_mode(bladeRF.ref, C.bladerf_channel(channel), &mode))
+	err := operationError(C.bladerf_get_gain_mode(bladeRF.ref, C.bladerf_channel(channel), &mode), "get_gain_mode", "ch", channel)
 
 	if err != nil {
 		return 0, err
@@ -539,7 +655,7 @@ func (bladeRF *BladeRF) SetGainStage(channel Channel, stage string, gain int) er
 	val := C.CString(stage)
 	defer C.free(unsafe.Pointer(val))
 
-	return GetError(C.bladerf_set_gain_stage(bladeRF.ref, C.bladerf_channel(channel), val, C.bladerf_gain(gain)))
+	return operationError(C.bladerf_set_gain_stage(bladeRF.ref, C.bladerf_channel(channel), val, C.bladerf_gain(gain)), "set_gain_stage", "ch", channel, "stage", stage, "gain", gain)
 }
 
 func (bladeRF *BladeRF) GetGainStageRange(channel Channel, stage string) (Range, error) {
@@ -547,7 +663,7 @@ func (bladeRF *BladeRF) GetGainStageRange(channel Channel, stage string) (Range,
 	defer C.free(unsafe.Pointer(val))
 
 	var _range *C.struct_bladerf_range
-	err := GetError(C.bladerf_get_gain_stage_range(bladeRF.ref, C.bladerf_channel(channel), val, &_range))
+	err := operationError(C.bladerf_get_gain_stage_range(bladeRF.ref, C.bladerf_channel(channel), val, &_range), "get_gain_stage_range", "ch", channel, "stage", stage)
 
 	if err != nil {
 		return Range{}, err
@@ -558,7 +674,7 @@ func (bladeRF *BladeRF) GetGainStageRange(channel Channel, stage string) (Range,
 
 func (bladeRF *BladeRF) GetGainRange(channel Channel) (Range, error) {
 	var _range *C.struct_bladerf_range
-	err := GetError(C.bladerf_get_gain_range(bladeRF.ref, C.bladerf_channel(channel), &_range))
+	err := operationError(C.bladerf_get_gain_range(bladeRF.ref, C.bladerf_channel(channel), &_range), "get_gain_range", "ch", channel)
 
 	if err != nil {
 		return Range{}, err
@@ -571,28 +687,28 @@ func (bladeRF *BladeRF) GetNumberOfGainStages(channel Channel) (int, error) {
 	countOrCode := C.bladerf_get_gain_stages(bladeRF.ref, C.bladerf_channel(channel), nil, 0)
 
 	if countOrCode < 0 {
-		return 0, GetError(countOrCode)
+		return 0, operationError(countOrCode, "get_gain_stages", "ch", channel)
 	}
 
 	return int(countOrCode), nil
 }
 
 func (bladeRF *BladeRF) SetCorrection(channel Channel, correction Correction, correctionValue int16) error {
-	return GetError(C.bladerf_set_correction(
+	return operationError(C.bladerf_set_correction(
 		bladeRF.ref,
 		C.bladerf_channel(channel),
 		C.bladerf_correction(correction),
-		C.bladerf_correction_value(correctionValue)),
+		C.bladerf_correction_value(correctionValue)), "set_correction", "ch", channel, "corr", correction, "value", correctionValue,
 	)
 }
 
 func (bladeRF *BladeRF) GetCorrection(channel Channel, correction Correction) (int16, error) {
 	var correctionValue C.int16_t
-	err := GetError(C.bladerf_get_correction(
+	err := operationError(C.bladerf_get_correction(
 		bladeRF.ref,
 		C.bladerf_channel(channel),
 		C.bladerf_correction(correction),
-		&correctionValue),
+		&correctionValue), "get_correction", "ch", channel, "corr", correction,
 	)
 
 	if err != nil {
@@ -602,6 +718,16 @@ func (bladeRF *BladeRF) GetCorrection(channel Channel, correction Correction) (i
 	return int16(correctionValue), nil
 }
 
+// SetCorrectionTable installs a table of corrections that SetFrequency
+// applies after every successful tune. A nil table turns this off.
+func (bladeRF *BladeRF) SetCorrectionTable(table *CorrectionTable) {
+	bladeRF.correctionTable = table
+}
+
+func (bladeRF *BladeRF) GetCorrectionTable() *CorrectionTable {
+	return bladeRF.correctionTable
+}
+
 func (backend *Backend) String() string {
 	return C.GoString(C.bladerf_backend_str(C.bladerf_backend(*backend)))
 }
@@ -615,19 +741,19 @@ func SetUSBResetOnOpen(enabled bool) {
 }
 
 func (bladeRF *BladeRF) GetSerial() (string, error) {
-	var serial C.char
-	err := GetError(C.bladerf_get_serial(bladeRF.ref, &serial))
+	var serial [C.BLADERF_SERIAL_LENGTH]C.char
+	err := operationError(C.bladerf_get_serial(bladeRF.ref, &serial[0]), "get_serial")
 
 	if err != nil {
 		return "", err
 	}
 
-	return C.GoString(&serial), nil
+	return C.GoString(&serial[0]), nil
 }
 
 func (bladeRF *BladeRF) GetSerialStruct() (Serial, error) {
 	var serial C.struct_bladerf_serial
-	err := GetError(C.bladerf_get_serial_struct(bladeRF.ref, &serial))
+	err := operationError(C.bladerf_get_serial_struct(bladeRF.ref, &serial), "get_serial_struct")
 
 	if err != nil {
 		return Serial{}, err
@@ -636,52 +762,43 @@ func (bladeRF *BladeRF) GetSerialStruct() (Serial, error) {
 	return NewSerial(&serial), nil
 }
 
+// GetGainStages passes libbladeRF an array with room for one string pointer
+// per stage. The stage names themselves stay owned by libbladeRF.
 func (bladeRF *BladeRF) GetGainStages(channel Channel) ([]string, error) {
-	var stagePtr *C.char
 	numberOfGainStages, err := bladeRF.GetNumberOfGainStages(channel)
 
 	if err != nil {
 		return nil, err
 	}
 
+	if numberOfGainStages == 0 {
+		return make([]string, 0), nil
+	}
+
+	stagePtrs := (**C.char)(C.malloc(C.size_t(uintptr(numberOfGainStages) * unsafe.Sizeof(uintptr(0)))))
+	defer C.free(unsafe.Pointer(stagePtrs))
+
 	countOrCode := C.bladerf_get_gain_stages(
 		bladeRF.ref,
 		C.bladerf_channel(channel),
-		&stagePtr,
+		stagePtrs,
 		C.size_t(numberOfGainStages),
 	)
 
 	if countOrCode < 0 {
-		return nil, GetError(countOrCode)
-	}
-
-	if countOrCode == 0 {
-		return make([]string, 0), nil
+		return nil, operationError(countOrCode, "get_gain_stages", "ch", channel)
 	}
 
 	count := int(countOrCode)
-	var stages []string
 
-	if count > 0 {
-		offset := uint32(0)
-
-		for i := 0; i < count; i++ {
-			var stage []rune
-			finish := false
-
-			for !finish {
-				char := *(*C.char)(unsafe.Pointer(uintptr(unsafe.Pointer(stagePtr)) + (C.sizeof_char * uintptr(offset))))
+	if count > numberOfGainStages {
+		count = numberOfGainStages
+	}
 
-				if char != 0 {
-					stage = append(stage, rune(char))
-				} else {
-					finish = true
-					stages = append(stages, string(stage))
-				}
+	stages := make([]string, count)
 
-				offset++
-			}
-		}
+	for i, stage := range (*[1 << 20]*C.char)(unsafe.Pointer(stagePtrs))[:count:count] {
+		stages[i] = C.GoString(stage)
 	}
 
 	return stages, nil
@@ -694,7 +811,7 @@ func (bladeRF *BladeRF) GetGainModes(channel Channel) ([]GainModes, error) {
 	countOrCode := C.bladerf_get_gain_modes(bladeRF.ref, C.bladerf_channel(channel), &gainMode)
 
 	if countOrCode < 0 {
-		return nil, GetError(countOrCode)
+		return nil, operationError(countOrCode, "get_gain_modes", "ch", channel)
 	}
 
 	if countOrCode == 0 {
@@ -723,7 +840,7 @@ func (bladeRF *BladeRF) GetLoopbackModes() ([]LoopbackModes, error) {
 	countOrCode := C.bladerf_get_loopback_modes(bladeRF.ref, &loopbackMode)
 
 	if countOrCode < 0 {
-		return nil, GetError(countOrCode)
+		return nil, operationError(countOrCode, "get_loopback_modes")
 	}
 
 	if countOrCode == 0 {
@@ -748,24 +865,40 @@ func (bladeRF *BladeRF) GetLoopbackModes() ([]LoopbackModes, error) {
 }
 
 func (bladeRF *BladeRF) SetGainMode(channel Channel, mode GainMode) error {
-	return GetError(C.bladerf_set_gain_mode(bladeRF.ref, C.bladerf_channel(channel), C.bladerf_gain_mode(mode)))
+	return operationError(C.bladerf_set_gain_mode(bladeRF.ref, C.bladerf_channel(channel), C.bladerf_gain_mode(mode)), "set_gain_mode", "ch", channel, "mode", mode)
 }
 
 func (bladeRF *BladeRF) EnableModule(channel Channel) error {
-	return GetError(C.bladerf_enable_module(bladeRF.ref, C.bladerf_channel(channel), true))
+	return bladeRF.enableModule(channel, true)
 }
 
 func (bladeRF *BladeRF) DisableModule(channel Channel) error {
-	return GetError(C.bladerf_enable_module(bladeRF.ref, C.bladerf_channel(channel), false))
+	return bladeRF.enableModule(channel, false)
+}
+
+func (bladeRF *BladeRF) enableModule(channel Channel, enable bool) error {
+	err := operationError(C.bladerf_enable_module(bladeRF.ref, C.bladerf_channel(channel), C.bool(enable)), "enable_module", "ch", channel, "enable", enable)
+
+	if err == nil && bladeRF.enabledModules != nil {
+		bladeRF.enabledModules[channel] = enable
+	}
+
+	return err
+}
+
+// IsModuleEnabled reports whether channel was enabled through this handle.
+// libbladeRF has no getter for it, and every module is disabled on open.
+func (bladeRF *BladeRF) IsModuleEnabled(channel Channel) bool {
+	return bladeRF.enabledModules[channel]
 }
 
 func (bladeRF *BladeRF) TriggerInit(channel Channel, signal TriggerSignal) (Trigger, error) {
 	var trigger C.struct_bladerf_trigger
-	err := GetError(C.bladerf_trigger_init(
+	err := operationError(C.bladerf_trigger_init(
 		bladeRF.ref,
 		C.bladerf_channel(channel),
 		C.bladerf_trigger_signal(signal),
-		&trigger),
+		&trigger), "trigger_init", "ch", channel, "signal", signal,
 	)
 
 	if err != nil {
@@ -776,11 +909,11 @@ func (bladeRF *BladeRF) TriggerInit(channel Channel, signal TriggerSignal) (Trig
 }
 
 func (bladeRF *BladeRF) TriggerArm(trigger Trigger, arm bool, resV1 uint64, resV2 uint64) error {
-	return GetError(C.bladerf_trigger_arm(bladeRF.ref, trigger.ref, C.bool(arm), C.uint64_t(resV1), C.uint64_t(resV2)))
+	return operationError(C.bladerf_trigger_arm(bladeRF.ref, trigger.ref, C.bool(arm), C.uint64_t(resV1), C.uint64_t(resV2)), "trigger_arm", "arm", arm)
 }
 
 func (bladeRF *BladeRF) TriggerFire(trigger Trigger) error {
-	return GetError(C.bladerf_trigger_fire(bladeRF.ref, trigger.ref))
+	return operationError(C.bladerf_trigger_fire(bladeRF.ref, trigger.ref), "trigger_fire")
 }
 
 func (bladeRF *BladeRF) TriggerState(trigger Trigger) (bool, bool, bool, uint64, uint64, error) {
@@ -790,14 +923,14 @@ func (bladeRF *BladeRF) TriggerState(trigger Trigger) (bool, bool, bool, uint64,
 	var resV1 C.uint64_t
 	var resV2 C.uint64_t
 
-	err := GetError(C.bladerf_trigger_state(
+	err := operationError(C.bladerf_trigger_state(
 		bladeRF.ref,
 		trigger.ref,
 		&isArmed,
 		&hasFired,
 		&fireRequested,
 		&resV1,
-		&resV2),
+		&resV2), "trigger_state",
 	)
 
 	if err != nil {
@@ -811,22 +944,68 @@ func (trigger *Trigger) SetRole(role TriggerRole) {
 	(*trigger.ref).role = C.bladerf_trigger_role(role)
 }
 
+func samplesPointer(buf []int16) unsafe.Pointer {
+	if len(buf) == 0 {
+		return nil
+	}
+
+	return unsafe.Pointer(&buf[0])
+}
+
+func samples8Pointer(buf []int8) unsafe.Pointer {
+	if len(buf) == 0 {
+		return nil
+	}
+
+	return unsafe.Pointer(&buf[0])
+}
+
+// checkSyncFormat refuses a buffer whose sample width does not match the
+// format given to SyncConfig, since libbladeRF would read or write past it.
+func (bladeRF *BladeRF) checkSyncFormat(direction Direction, sampleSize int, operation string) error {
+	format, ok := bladeRF.syncFormats[direction]
+
+	if (ok || sampleSize == 2) && format.SampleSize() != sampleSize {
+		return exception.NewWithOperation(int(exception.Inval), operation, formatArguments("format", format))
+	}
+
+	return nil
+}
+
 func (bladeRF *BladeRF) SyncTX(input []int16, metadata Metadata, timeout uint) (Metadata, error) {
-	if metadata.ref == nil {
-		var ref C.struct_bladerf_metadata
-		metadata.ref = &ref
+	return bladeRF.SyncTXFrom(input, metadata, timeout)
+}
+
+// SyncTXFrom transmits the interleaved samples in buf without copying them.
+// buf may be a Go slice or the Samples of a SampleBuffer.
+func (bladeRF *BladeRF) SyncTXFrom(buf []int16, metadata Metadata, timeout uint) (Metadata, error) {
+	if err := bladeRF.checkSyncFormat(Tx, 4, "sync_tx"); err != nil {
+		return metadata, err
 	}
 
-	numberOfSample := len(input)
-	buf := (*C.uint16_t)(C.malloc((C.size_t)(C.sizeof_uint16_t * uintptr(numberOfSample))))
-	defer C.free(unsafe.Pointer(buf))
+	return bladeRF.syncTX(samplesPointer(buf), len(buf)/2, metadata, timeout)
+}
+
+func (bladeRF *BladeRF) SyncTX8(input []int8, metadata Metadata, timeout uint) (Metadata, error) {
+	return bladeRF.SyncTX8From(input, metadata, timeout)
+}
+
+// SyncTX8From is SyncTXFrom for the SC8_Q7 formats.
+func (bladeRF *BladeRF) SyncTX8From(buf []int8, metadata Metadata, timeout uint) (Metadata, error) {
+	if err := bladeRF.checkSyncFormat(Tx, 2, "sync_tx"); err != nil {
+		return metadata, err
+	}
 
-	for i := 0; i < numberOfSample; i++ {
-		addr := (*C.uint16_t)(unsafe.Pointer(uintptr(unsafe.Pointer(buf)) + (C.sizeof_uint16_t * uintptr(i))))
-		*addr = (C.uint16_t)(input[i])
+	return bladeRF.syncTX(samples8Pointer(buf), len(buf)/2, metadata, timeout)
+}
+
+func (bladeRF *BladeRF) syncTX(buf unsafe.Pointer, numberOfSample int, metadata Metadata, timeout uint) (Metadata, error) {
+	if metadata.ref == nil {
+		var ref C.struct_bladerf_metadata
+		metadata.ref = &ref
 	}
 
-	err := GetError(C.bladerf_sync_tx(bladeRF.ref, unsafe.Pointer(buf), C.uint(numberOfSample/2), metadata.ref, C.uint(timeout)))
+	err := operationError(C.bladerf_sync_tx(bladeRF.ref, buf, C.uint(numberOfSample), metadata.ref, C.uint(timeout)), "sync_tx", "samples", numberOfSample)
 
 	if err != nil {
 		return metadata, err
@@ -836,27 +1015,64 @@ func (bladeRF *BladeRF) SyncTX(input []int16, metadata Metadata, timeout uint) (
 }
 
 func (bladeRF *BladeRF) SyncRX(bufferSize uintptr, metadata Metadata, timeout uint) ([]int16, Metadata, error) {
+	results := make([]int16, bufferSize*2)
+	count, metadata, err := bladeRF.SyncRXInto(results, metadata, timeout)
+
+	if err != nil {
+		return nil, metadata, err
+	}
+
+	return results[:count*2], metadata, nil
+}
+
+// SyncRXInto fills buf with len(buf)/2 interleaved samples without any
+// intermediate allocation and returns the number of samples received.
+func (bladeRF *BladeRF) SyncRXInto(buf []int16, metadata Metadata, timeout uint) (int, Metadata, error) {
+	if err := bladeRF.checkSyncFormat(Rx, 4, "sync_rx"); err != nil {
+		return 0, metadata, err
+	}
+
+	return bladeRF.syncRX(samplesPointer(buf), len(buf)/2, metadata, timeout)
+}
+
+func (bladeRF *BladeRF) SyncRX8(bufferSize uintptr, metadata Metadata, timeout uint) ([]int8, Metadata, error) {
+	results := make([]int8, bufferSize*2)
+	count, metadata, err := bladeRF.SyncRX8Into(results, metadata, timeout)
+
+	if err != nil {
+		return nil, metadata, err
+	}
+
+	return results[:count*2], metadata, nil
+}
+
+// SyncRX8Into is SyncRXInto for the SC8_Q7 formats.
+func (bladeRF *BladeRF) SyncRX8Into(buf []int8, metadata Metadata, timeout uint) (int, Metadata, error) {
+	if err := bladeRF.checkSyncFormat(Rx, 2, "sync_rx"); err != nil {
+		return 0, metadata, err
+	}
+
+	return bladeRF.syncRX(samples8Pointer(buf), len(buf)/2, metadata, timeout)
+}
+
+func (bladeRF *BladeRF) syncRX(buf unsafe.Pointer, numberOfSample int, metadata Metadata, timeout uint) (int, Metadata, error) {
 	if metadata.ref == nil {
 		var ref C.struct_bladerf_metadata
 		metadata.ref = &ref
 	}
 
-	start := C.malloc(C.size_t(C.sizeof_int16_t * bufferSize * 2 * 1))
-	defer C.free(unsafe.Pointer(start))
-	err := GetError(C.bladerf_sync_rx(bladeRF.ref, start, C.uint(bufferSize), metadata.ref, C.uint(timeout)))
+	metadata.ref.actual_count = 0
+	err := operationError(C.bladerf_sync_rx(bladeRF.ref, buf, C.uint(numberOfSample), metadata.ref, C.uint(timeout)), "sync_rx", "samples", numberOfSample)
 
 	if err != nil {
-		return nil, metadata, err
+		return 0, metadata, err
 	}
 
-	var results []int16
-
-	for i := 0; i < (int(metadata.ref.actual_count)); i++ {
-		n := (*C.int16_t)(unsafe.Pointer(uintptr(start) + (C.sizeof_int16_t * uintptr(i))))
-		results = append(results, int16(*n))
+	if metadata.ref.actual_count != 0 {
+		numberOfSample = int(metadata.ref.actual_count)
 	}
 
-	return results, LoadMetadata(metadata.ref), nil
+	return numberOfSample, LoadMetadata(metadata.ref), nil
 }
 
 func (bladeRF *BladeRF) InitStream(
@@ -865,13 +1081,45 @@ func (bladeRF *BladeRF) InitStream(
 	samplesPerBuffer int,
 	numTransfers int,
 	callback func(data []int16) GoStream,
+) (Stream, error) {
+	if format.SampleSize() != 4 {
+		return Stream{}, exception.NewWithOperation(int(exception.Inval), "init_stream", formatArguments("format", format))
+	}
+
+	userData := NewUserData(callback, samplesPerBuffer)
+	return bladeRF.initStream(format, numBuffers, samplesPerBuffer, numTransfers, &userData)
+}
+
+// InitStream8 is InitStream for the SC8_Q7 formats, handing the callback
+// 2*samplesPerBuffer interleaved 8-bit values per buffer.
+func (bladeRF *BladeRF) InitStream8(
+	format Format,
+	numBuffers int,
+	samplesPerBuffer int,
+	numTransfers int,
+	callback func(data []int8) GoStream,
+) (Stream, error) {
+	if format.SampleSize() != 2 {
+		return Stream{}, exception.NewWithOperation(int(exception.Inval), "init_stream", formatArguments("format", format))
+	}
+
+	userData := UserData{callback8: callback, results8: make([]int8, samplesPerBuffer*2), bufferSize: samplesPerBuffer}
+	return bladeRF.initStream(format, numBuffers, samplesPerBuffer, numTransfers, &userData)
+}
+
+func (bladeRF *BladeRF) initStream(
+	format Format,
+	numBuffers int,
+	samplesPerBuffer int,
+	numTransfers int,
+	userData *UserData,
 ) (Stream, error) {
 	var buffers *unsafe.Pointer
 	var rxStream *C.struct_bladerf_stream
 
-	stream := Stream{ref: rxStream}
+	stream := Stream{ref: rxStream, userData: userData, handle: pointer.Save(userData)}
 
-	err := GetError(C.bladerf_init_stream(
+	err := operationError(C.bladerf_init_stream(
 		&((stream).ref),
 		bladeRF.ref,
 		(*[0]byte)((C.StreamCallback)),
@@ -880,23 +1128,114 @@ func (bladeRF *BladeRF) InitStream(
 		C.bladerf_format(format),
 		C.ulong(samplesPerBuffer),
 		C.ulong(numTransfers),
-		pointer.Save(NewUserData(callback, samplesPerBuffer)),
-	))
+		stream.handle,
+	), "init_stream", "buffers", numBuffers, "samples", samplesPerBuffer, "transfers", numTransfers)
 
 	if err != nil {
+		pointer.Unref(stream.handle)
 		return Stream{}, err
 	}
 
+	userData.buffers = (*[1 << 28]unsafe.Pointer)(unsafe.Pointer(buffers))[:numBuffers:numBuffers]
+
 	return stream, nil
 }
 
 func (stream *Stream) DeInit() {
+	if stream.simulator != nil {
+		return
+	}
+
 	C.bladerf_deinit_stream(stream.ref)
+
+	if stream.handle != nil {
+		pointer.Unref(stream.handle)
+		stream.handle = nil
+	}
+}
+
+// Buffers returns the sample buffers owned by the stream. In transmit mode
+// these are the only buffers that may be passed to SubmitBuffer.
+func (stream *Stream) Buffers() [][]int16 {
+	if stream.simulator != nil {
+		return stream.simulator.buffers
+	}
+
+	buffers := make([][]int16, len(stream.userData.buffers))
+
+	for i, buffer := range stream.userData.buffers {
+		buffers[i] = stream.userData.buffer(buffer)
+	}
+
+	return buffers
+}
+
+// Buffers8 is Buffers for streams created with InitStream8.
+func (stream *Stream) Buffers8() [][]int8 {
+	if stream.simulator != nil {
+		return stream.simulator.buffers8
+	}
+
+	buffers := make([][]int8, len(stream.userData.buffers))
+
+	for i, buffer := range stream.userData.buffers {
+		buffers[i] = stream.userData.buffer8(buffer)
+	}
+
+	return buffers
+}
+
+func (stream *Stream) SubmitBuffer8(buffer []int8, timeout uint) error {
+	if len(buffer) == 0 {
+		return exception.NewWithOperation(int(exception.Inval), "submit_stream_buffer", formatArguments("samples", 0, "timeout", timeout))
+	}
+
+	if stream.simulator != nil {
+		return stream.simulator.submit8(buffer)
+	}
+
+	return operationError(C.bladerf_submit_stream_buffer(stream.ref, unsafe.Pointer(&buffer[0]), C.uint(timeout)), "submit_stream_buffer", "timeout", timeout)
+}
+
+func (stream *Stream) SubmitBuffer8NonBlocking(buffer []int8) error {
+	if len(buffer) == 0 {
+		return exception.NewWithOperation(int(exception.Inval), "submit_stream_buffer_nb", formatArguments("samples", 0))
+	}
+
+	if stream.simulator != nil {
+		return stream.simulator.submit8(buffer)
+	}
+
+	return operationError(C.bladerf_submit_stream_buffer_nb(stream.ref, unsafe.Pointer(&buffer[0])), "submit_stream_buffer_nb")
+}
+
+func (stream *Stream) SubmitBuffer(buffer []int16, timeout uint) error {
+	if len(buffer) == 0 {
+		return exception.NewWithOperation(int(exception.Inval), "submit_stream_buffer", formatArguments("samples", 0, "timeout", timeout))
+	}
+
+	if stream.simulator != nil {
+		return stream.simulator.submit(buffer)
+	}
+
+	return operationError(C.bladerf_submit_stream_buffer(stream.ref, unsafe.Pointer(&buffer[0]), C.uint(timeout)), "submit_stream_buffer", "timeout", timeout)
+}
+
+func (stream *Stream) SubmitBufferNonBlocking(buffer []int16) error {
+	if len(buffer) == 0 {
+		return exception.NewWithOperation(int(exception.Inval), "submit_stream_buffer_nb", formatArguments("samples", 0))
+	}
+
+	if stream.simulator != nil {
+		return stream.simulator.submit(buffer)
+	}
+
+	return operationError(C.bladerf_submit_stream_buffer_nb(stream.ref, unsafe.Pointer(&buffer[0])), "submit_stream_buffer_nb")
 }
 
 func (bladeRF *BladeRF) GetStreamTimeout(direction Direction) (uint, error) {
 	var timeout C.uint
-	err := GetError(C.bladerf_get_stream_timeout(bladeRF.ref, C.bladerf_direction(direction), &timeout))
+	err := operationError(C.bladerf_get_stream_timeout(bladeRF.ref, C.bladerf_direction(direction), &timeout), "get_stream_timeout", "dir", direction)
 
 	if err != nil {
 		return 0, err
@@ -906,7 +1245,7 @@ func (bladeRF *BladeRF) GetStreamTimeout(direction Direction) (uint, error) {
 }
 
 func (bladeRF *BladeRF) SetStreamTimeout(direction Direction, timeout uint) error {
-	return GetError(C.bladerf_set_stream_timeout(bladeRF.ref, C.bladerf_direction(direction), C.uint(timeout)))
+	return operationError(C.bladerf_set_stream_timeout(bladeRF.ref, C.bladerf_direction(direction), C.uint(timeout)), "set_stream_timeout", "dir", direction, "timeout", timeout)
 }
 
 func (bladeRF *BladeRF) SyncConfig(
@@ -917,28 +1256,62 @@ func (bladeRF *BladeRF) SyncConfig(
 	numTransfers uint,
 	timeout uint,
 ) error {
-	return GetError(C.bladerf_sync_config(
+	err := operationError(C.bladerf_sync_config(
 		bladeRF.ref,
 		C.bladerf_channel_layout(layout),
 		C.bladerf_format(format),
 		C.uint(numBuffers),
 		C.uint(bufferSize),
 		C.uint(numTransfers),
-		C.uint(timeout)),
-	)
+		C.uint(timeout),
+	), "sync_config", "layout", layout, "format", format, "buffers", numBuffers, "size", bufferSize, "transfers", numTransfers)
+
+	if err != nil {
+		return err
+	}
+
+	if bladeRF.syncFormats != nil {
+		bladeRF.syncFormats[layoutDirection(layout)] = format
+	}
+
+	if bladeRF.syncBufferSizes != nil {
+		bladeRF.syncBufferSizes[layoutDirection(layout)] = bufferSize
+	}
+
+	return nil
+}
+
+func layoutDirection(layout ChannelLayout) Direction {
+	if layout == TxX1 || layout == TxX2 {
+		return Tx
+	}
+
+	return Rx
 }
 
 func (stream *Stream) Start(layout ChannelLayout) error {
-	return GetError(C.bladerf_stream(stream.ref, C.bladerf_channel_layout(layout)))
+	if stream.simulator != nil {
+		return stream.simulator.start(layout)
+	}
+
+	stream.userData.direction = layoutDirection(layout)
+	return operationError(C.bladerf_stream(stream.ref, C.bladerf_channel_layout(layout)), "stream", "layout", layout)
 }
 
 func (bladeRF *BladeRF) AttachExpansionBoard(expansionBoard ExpansionBoard) error {
-	return GetError(C.bladerf_expansion_attach(bladeRF.ref, C.bladerf_xb(expansionBoard)))
+	err := operationError(C.bladerf_expansion_attach(bladeRF.ref, C.bladerf_xb(expansionBoard)), "expansion_attach", "xb", expansionBoard)
+
+	if err != nil {
+		return err
+	}
+
+	bladeRF.expansionBoard = expansionBoard
+	return nil
 }
 
 func (bladeRF *BladeRF) GetAttachedExpansionBoard() (ExpansionBoard, error) {
 	var expansionBoard C.bladerf_xb
-	err := GetError(C.bladerf_expansion_get_attached(bladeRF.ref, &expansionBoard))
+	err := operationError(C.bladerf_expansion_get_attached(bladeRF.ref, &expansionBoard), "expansion_get_attached")
 
 	if err != nil {
 		return 0, err
@@ -947,13 +1320,452 @@ func (bladeRF *BladeRF) GetAttachedExpansionBoard() (ExpansionBoard, error) {
 	return ExpansionBoard(expansionBoard), nil
 }
 
+func (bladeRF *BladeRF) XB200SetFilterbank(channel Channel, filter XB200Filter) error {
+	return operationError(C.bladerf_xb200_set_filterbank(bladeRF.ref, C.bladerf_channel(channel), C.bladerf_xb200_filter(filter)), "xb200_set_filterbank", "ch", channel, "filter", filter)
+}
+
+func (bladeRF *BladeRF) XB200GetFilterbank(channel Channel) (XB200Filter, error) {
+	var filter C.bladerf_xb200_filter
+	err := operationError(C.bladerf_xb200_get_filterbank(bladeRF.ref, C.bladerf_channel(channel), &filter), "xb200_get_filterbank", "ch", channel)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return XB200Filter(filter), nil
+}
+
+func (bladeRF *BladeRF) XB200SetPath(channel Channel, path XB200Path) error {
+	return operationError(C.bladerf_xb200_set_path(bladeRF.ref, C.bladerf_channel(channel), C.bladerf_xb200_path(path)), "xb200_set_path", "ch", channel, "path", path)
+}
+
+func (bladeRF *BladeRF) XB200GetPath(channel Channel) (XB200Path, error) {
+	var path C.bladerf_xb200_path
+	err := operationError(C.bladerf_xb200_get_path(bladeRF.ref, C.bladerf_channel(channel), &path), "xb200_get_path", "ch", channel)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return XB200Path(path), nil
+}
+
+func (bladeRF *BladeRF) XB300SetTrx(trx XB300Trx) error {
+	return operationError(C.bladerf_xb300_set_trx(bladeRF.ref, C.bladerf_xb300_trx(trx)), "xb300_set_trx", "trx", trx)
+}
+
+func (bladeRF *BladeRF) XB300GetTrx() (XB300Trx, error) {
+	var trx C.bladerf_xb300_trx
+	err := operationError(C.bladerf_xb300_get_trx(bladeRF.ref, &trx), "xb300_get_trx")
+
+	if err != nil {
+		return XB300TrxInvalid, err
+	}
+
+	return XB300Trx(trx), nil
+}
+
+func (bladeRF *BladeRF) XB300SetAmplifierEnable(amplifier XB300Amplifier, enable bool) error {
+	return operationError(C.bladerf_xb300_set_amplifier_enable(bladeRF.ref, C.bladerf_xb300_amplifier(amplifier), C.bool(enable)), "xb300_set_amplifier_enable", "amp", amplifier, "enable", enable)
+}
+
+func (bladeRF *BladeRF) XB300GetAmplifierEnable(amplifier XB300Amplifier) (bool, error) {
+	var enable C.bool
+	err := operationError(C.bladerf_xb300_get_amplifier_enable(bladeRF.ref, C.bladerf_xb300_amplifier(amplifier), &enable), "xb300_get_amplifier_enable", "amp", amplifier)
+
+	if err != nil {
+		return false, err
+	}
+
+	return bool(enable), nil
+}
+
+// XB300GetOutputPower reads the PA power detector, in volts.
+func (bladeRF *BladeRF) XB300GetOutputPower() (float32, error) {
+	var power C.float
+	err := operationError(C.bladerf_xb300_get_output_power(bladeRF.ref, &power), "xb300_get_output_power")
+
+	if err != nil {
+		return 0, err
+	}
+
+	return float32(power), nil
+}
+
+func (bladeRF *BladeRF) SetBiasTee(channel Channel, enable bool) error {
+	return operationError(C.bladerf_set_bias_tee(bladeRF.ref, C.bladerf_channel(channel), C.bool(enable)), "set_bias_tee", "ch", channel, "enable", enable)
+}
+
+func (bladeRF *BladeRF) GetBiasTee(channel Channel) (bool, error) {
+	var enable C.bool
+	err := operationError(C.bladerf_get_bias_tee(bladeRF.ref, C.bladerf_channel(channel), &enable), "get_bias_tee", "ch", channel)
+
+	if err != nil {
+		return false, err
+	}
+
+	return bool(enable), nil
+}
+
+func (bladeRF *BladeRF) SetClockSelect(selection ClockSelect) error {
+	return operationError(C.bladerf_set_clock_select(bladeRF.ref, C.bladerf_clock_select(selection)), "set_clock_select", "sel", selection)
+}
+
+func (bladeRF *BladeRF) GetClockSelect() (ClockSelect, error) {
+	var selection C.bladerf_clock_select
+	err := operationError(C.bladerf_get_clock_select(bladeRF.ref, &selection), "get_clock_select")
+
+	if err != nil {
+		return 0, err
+	}
+
+	return ClockSelect(selection), nil
+}
+
+func (bladeRF *BladeRF) SetClockOutput(enable bool) error {
+	return operationError(C.bladerf_set_clock_output(bladeRF.ref, C.bool(enable)), "set_clock_output", "enable", enable)
+}
+
+func (bladeRF *BladeRF) GetClockOutput() (bool, error) {
+	var state C.bool
+	err := operationError(C.bladerf_get_clock_output(bladeRF.ref, &state), "get_clock_output")
+
+	if err != nil {
+		return false, err
+	}
+
+	return bool(state), nil
+}
+
+func (bladeRF *BladeRF) SetPllEnable(enable bool) error {
+	return operationError(C.bladerf_set_pll_enable(bladeRF.ref, C.bool(enable)), "set_pll_enable", "enable", enable)
+}
+
+func (bladeRF *BladeRF) GetPllEnable() (bool, error) {
+	var enabled C.bool
+	err := operationError(C.bladerf_get_pll_enable(bladeRF.ref, &enabled), "get_pll_enable")
+
+	if err != nil {
+		return false, err
+	}
+
+	return bool(enabled), nil
+}
+
+func (bladeRF *BladeRF) SetPllRefClk(frequency uint64) error {
+	return operationError(C.bladerf_set_pll_refclk(bladeRF.ref, C.uint64_t(frequency)), "set_pll_refclk", "freq", hertz(frequency))
+}
+
+func (bladeRF *BladeRF) GetPllRefClk() (uint64, error) {
+	var frequency C.uint64_t
+	err := operationError(C.bladerf_get_pll_refclk(bladeRF.ref, &frequency), "get_pll_refclk")
+
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(frequency), nil
+}
+
+func (bladeRF *BladeRF) GetPllRefClkRange() (Range, error) {
+	var _range *C.struct_bladerf_range
+	err := operationError(C.bladerf_get_pll_refclk_range(bladeRF.ref, &_range), "get_pll_refclk_range")
+
+	if err != nil {
+		return Range{}, err
+	}
+
+	return NewRange(_range), nil
+}
+
+func (bladeRF *BladeRF) GetPllLockState() (bool, error) {
+	var locked C.bool
+	err := operationError(C.bladerf_get_pll_lock_state(bladeRF.ref, &locked), "get_pll_lock_state")
+
+	if err != nil {
+		return false, err
+	}
+
+	return bool(locked), nil
+}
+
+// GetPmicRegister reads the INA219 power monitor. Voltages are returned in
+// volts, current in amperes and power in watts; the configuration and
+// calibration registers are returned as their raw 16 bit value.
+func (bladeRF *BladeRF) GetPmicRegister(register PmicRegister) (float64, error) {
+	if register == PmicConfiguration || register == PmicCalibration {
+		var val C.uint16_t
+		err := operationError(C.bladerf_get_pmic_register(bladeRF.ref, C.bladerf_pmic_register(register), unsafe.Pointer(&val)), "get_pmic_register", "reg", register)
+
+		if err != nil {
+			return 0, err
+		}
+
+		return float64(val), nil
+	}
+
+	var val C.float
+	err := operationError(C.bladerf_get_pmic_register(bladeRF.ref, C.bladerf_pmic_register(register), unsafe.Pointer(&val)), "get_pmic_register", "reg", register)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(val), nil
+}
+
+func (bladeRF *BladeRF) GetPowerSource() (PowerSource, error) {
+	var source C.bladerf_power_sources
+	err := operationError(C.bladerf_get_power_source(bladeRF.ref, &source), "get_power_source")
+
+	if err != nil {
+		return PsUnknown, err
+	}
+
+	return PowerSource(source), nil
+}
+
+func (bladeRF *BladeRF) LmsRead(address uint8) (uint8, error) {
+	var val C.uint8_t
+	err := operationError(C.bladerf_lms_read(bladeRF.ref, C.uint8_t(address), &val), "lms_read", "addr", fmt.Sprintf("0x%02x", address))
+
+	if err != nil {
+		return 0, err
+	}
+
+	return uint8(val), nil
+}
+
+func (bladeRF *BladeRF) LmsWrite(address uint8, val uint8) error {
+	return operationError(C.bladerf_lms_write(bladeRF.ref, C.uint8_t(address), C.uint8_t(val)), "lms_write", "addr", fmt.Sprintf("0x%02x", address), "val", fmt.Sprintf("0x%02x", val))
+}
+
+// LmsSetDcCals loads DC calibration values into the LMS6002D. Fields set
+// to -1 are left unchanged.
+func (bladeRF *BladeRF) LmsSetDcCals(dcCals LmsDcCals) error {
+	cals := C.struct_bladerf_lms_dc_cals{
+		lpf_tuning: C.int16_t(dcCals.LpfTuning),
+		tx_lpf_i:   C.int16_t(dcCals.TxLpfI),
+		tx_lpf_q:   C.int16_t(dcCals.TxLpfQ),
+		rx_lpf_i:   C.int16_t(dcCals.RxLpfI),
+		rx_lpf_q:   C.int16_t(dcCals.RxLpfQ),
+		dc_ref:     C.int16_t(dcCals.DcRef),
+		rxvga2a_i:  C.int16_t(dcCals.RxVga2aI),
+		rxvga2a_q:  C.int16_t(dcCals.RxVga2aQ),
+		rxvga2b_i:  C.int16_t(dcCals.RxVga2bI),
+		rxvga2b_q:  C.int16_t(dcCals.RxVga2bQ),
+	}
+
+	return operationError(C.bladerf_lms_set_dc_cals(bladeRF.ref, &cals), "lms_set_dc_cals")
+}
+
+func (bladeRF *BladeRF) LmsGetDcCals() (LmsDcCals, error) {
+	var cals C.struct_bladerf_lms_dc_cals
+	err := operationError(C.bladerf_lms_get_dc_cals(bladeRF.ref, &cals), "lms_get_dc_cals")
+
+	if err != nil {
+		return LmsDcCals{}, err
+	}
+
+	return LmsDcCals{
+		LpfTuning: int16(cals.lpf_tuning),
+		TxLpfI:    int16(cals.tx_lpf_i),
+		TxLpfQ:    int16(cals.tx_lpf_q),
+		RxLpfI:    int16(cals.rx_lpf_i),
+		RxLpfQ:    int16(cals.rx_lpf_q),
+		DcRef:     int16(cals.dc_ref),
+		RxVga2aI:  int16(cals.rxvga2a_i),
+		RxVga2aQ:  int16(cals.rxvga2a_q),
+		RxVga2bI:  int16(cals.rxvga2b_i),
+		RxVga2bQ:  int16(cals.rxvga2b_q),
+	}, nil
+}
+
+func (bladeRF *BladeRF) Si5338Read(address uint8) (uint8, error) {
+	var val C.uint8_t
+	err := operationError(C.bladerf_si5338_read(bladeRF.ref, C.uint8_t(address), &val), "si5338_read", "addr", address)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return uint8(val), nil
+}
+
+func (bladeRF *BladeRF) Si5338Write(address uint8, val uint8) error {
+	return operationError(C.bladerf_si5338_write(bladeRF.ref, C.uint8_t(address), C.uint8_t(val)), "si5338_write", "addr", address, "val", fmt.Sprintf("0x%02x", val))
+}
+
+func (bladeRF *BladeRF) Si5338SetTxFreq(frequency uint) error {
+	return operationError(C.bladerf_si5338_set_tx_freq(bladeRF.ref, C.uint(frequency)), "si5338_set_tx_freq", "freq", hertz(frequency))
+}
+
+func (bladeRF *BladeRF) Si5338SetRxFreq(frequency uint) error {
+	return operationError(C.bladerf_si5338_set_rx_freq(bladeRF.ref, C.uint(frequency)), "si5338_set_rx_freq", "freq", hertz(frequency))
+}
+
+// Si5338SetSmbFreq drives the SMB clock output at frequency.
+func (bladeRF *BladeRF) Si5338SetSmbFreq(frequency uint) error {
+	return operationError(C.bladerf_si5338_set_smb_freq(bladeRF.ref, C.uint(frequency)), "si5338_set_smb_freq", "freq", hertz(frequency))
+}
+
+// requireRfic fails with Unsupported on the bladeRF 1, which has an
+// LMS6002D instead of the AD9361 RFIC.
+func (bladeRF *BladeRF) requireRfic(operation string, arguments ...interface{}) error {
+	if bladeRF.GetBoardName() == "bladerf1" {
+		return exception.NewWithOperation(int(exception.Unsupported), operation, formatArguments(arguments...))
+	}
+
+	return nil
+}
+
+// GetRficRssi returns the preamble and symbol RSSI of channel, in dB.
+func (bladeRF *BladeRF) GetRficRssi(channel Channel) (int32, int32, error) {
+	if err := bladeRF.requireRfic("get_rfic_rssi", "ch", channel); err != nil {
+		return 0, 0, err
+	}
+
+	var preamble C.int32_t
+	var symbol C.int32_t
+	err := operationError(C.bladerf_get_rfic_rssi(bladeRF.ref, C.bladerf_channel(channel), &preamble, &symbol), "get_rfic_rssi", "ch", channel)
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return int32(preamble), int32(symbol), nil
+}
+
+// GetRficTemperature returns the AD9361 die temperature in degrees Celsius.
+func (bladeRF *BladeRF) GetRficTemperature() (float32, error) {
+	if err := bladeRF.requireRfic("get_rfic_temperature"); err != nil {
+		return 0, err
+	}
+
+	var temperature C.float
+	err := operationError(C.bladerf_get_rfic_temperature(bladeRF.ref, &temperature), "get_rfic_temperature")
+
+	if err != nil {
+		return 0, err
+	}
+
+	return float32(temperature), nil
+}
+
+func (bladeRF *BladeRF) GetRficCtrlOut() (uint8, error) {
+	if err := bladeRF.requireRfic("get_rfic_ctrl_out"); err != nil {
+		return 0, err
+	}
+
+	var ctrlOut C.uint8_t
+	err := operationError(C.bladerf_get_rfic_ctrl_out(bladeRF.ref, &ctrlOut), "get_rfic_ctrl_out")
+
+	if err != nil {
+		return 0, err
+	}
+
+	return uint8(ctrlOut), nil
+}
+
+func (bladeRF *BladeRF) GetRficRxFir() (RficRxFir, error) {
+	if err := bladeRF.requireRfic("get_rfic_rx_fir"); err != nil {
+		return 0, err
+	}
+
+	var fir C.bladerf_rfic_rxfir
+	err := operationError(C.bladerf_get_rfic_rx_fir(bladeRF.ref, &fir), "get_rfic_rx_fir")
+
+	if err != nil {
+		return 0, err
+	}
+
+	return RficRxFir(fir), nil
+}
+
+func (bladeRF *BladeRF) SetRficRxFir(fir RficRxFir) error {
+	if err := bladeRF.requireRfic("set_rfic_rx_fir", "fir", fir); err != nil {
+		return err
+	}
+
+	return operationError(C.bladerf_set_rfic_rx_fir(bladeRF.ref, C.bladerf_rfic_rxfir(fir)), "set_rfic_rx_fir", "fir", fir)
+}
+
+func (bladeRF *BladeRF) GetRficTxFir() (RficTxFir, error) {
+	if err := bladeRF.requireRfic("get_rfic_tx_fir"); err != nil {
+		return 0, err
+	}
+
+	var fir C.bladerf_rfic_txfir
+	err := operationError(C.bladerf_get_rfic_tx_fir(bladeRF.ref, &fir), "get_rfic_tx_fir")
+
+	if err != nil {
+		return 0, err
+	}
+
+	return RficTxFir(fir), nil
+}
+
+func (bladeRF *BladeRF) SetRficTxFir(fir RficTxFir) error {
+	if err := bladeRF.requireRfic("set_rfic_tx_fir", "fir", fir); err != nil {
+		return err
+	}
+
+	return operationError(C.bladerf_set_rfic_tx_fir(bladeRF.ref, C.bladerf_rfic_txfir(fir)), "set_rfic_tx_fir", "fir", fir)
+}
+
+func (bladeRF *BladeRF) GetRficRegister(address uint16) (uint8, error) {
+	if err := bladeRF.requireRfic("get_rfic_register", "addr", fmt.Sprintf("0x%03x", address)); err != nil {
+		return 0, err
+	}
+
+	var val C.uint8_t
+	err := operationError(C.bladerf_get_rfic_register(bladeRF.ref, C.uint16_t(address), &val), "get_rfic_register", "addr", fmt.Sprintf("0x%03x", address))
+
+	if err != nil {
+		return 0, err
+	}
+
+	return uint8(val), nil
+}
+
+func (bladeRF *BladeRF) SetRficRegister(address uint16, val uint8) error {
+	if err := bladeRF.requireRfic("set_rfic_register", "addr", fmt.Sprintf("0x%03x", address), "val", val); err != nil {
+		return err
+	}
+
+	return operationError(C.bladerf_set_rfic_register(bladeRF.ref, C.uint16_t(address), C.uint8_t(val)), "set_rfic_register", "addr", fmt.Sprintf("0x%03x", address), "val", val)
+}
+
+func (bladeRF *BladeRF) SetTxMute(channel Channel, mute bool) error {
+	if err := bladeRF.requireRfic("set_tx_mute", "ch", channel, "state", mute); err != nil {
+		return err
+	}
+
+	return operationError(C.bladerf_set_tx_mute(bladeRF.ref, C.bladerf_channel(channel), C.bool(mute)), "set_tx_mute", "ch", channel, "state", mute)
+}
+
+func (bladeRF *BladeRF) GetTxMute(channel Channel) (bool, error) {
+	if err := bladeRF.requireRfic("get_tx_mute", "ch", channel); err != nil {
+		return false, err
+	}
+
+	var mute C.bool
+	err := operationError(C.bladerf_get_tx_mute(bladeRF.ref, C.bladerf_channel(channel), &mute), "get_tx_mute", "ch", channel)
+
+	if err != nil {
+		return false, err
+	}
+
+	return bool(mute), nil
+}
+
 func (bladeRF *BladeRF) SetVctcxoTamerMode(mode VctcxoTamerMode) error {
-	return GetError(C.bladerf_set_vctcxo_tamer_mode(bladeRF.ref, C.bladerf_vctcxo_tamer_mode(mode)))
+	return operationError(C.bladerf_set_vctcxo_tamer_mode(bladeRF.ref, C.bladerf_vctcxo_tamer_mode(mode)), "set_vctcxo_tamer_mode", "mode", mode)
 }
 
 func (bladeRF *BladeRF) GetVctcxoTamerMode() (VctcxoTamerMode, error) {
 	var mode C.bladerf_vctcxo_tamer_mode
-	err := GetError(C.bladerf_get_vctcxo_tamer_mode(bladeRF.ref, &mode))
+	err := operationError(C.bladerf_get_vctcxo_tamer_mode(bladeRF.ref, &mode), "get_vctcxo_tamer_mode")
 
 	if err != nil {
 		return 0, err
@@ -964,7 +1776,7 @@ func (bladeRF *BladeRF) GetVctcxoTamerMode() (VctcxoTamerMode, error) {
 
 func (bladeRF *BladeRF) GetVctcxoTrim() (uint16, error) {
 	var trim C.uint16_t
-	err := GetError(C.bladerf_get_vctcxo_trim(bladeRF.ref, &trim))
+	err := operationError(C.bladerf_get_vctcxo_trim(bladeRF.ref, &trim), "get_vctcxo_trim")
 
 	if err != nil {
 		return 0, err
@@ -975,7 +1787,7 @@ func (bladeRF *BladeRF) GetVctcxoTrim() (uint16, error) {
 
 func (bladeRF *BladeRF) TrimDacRead() (uint16, error) {
 	var val C.uint16_t
-	err := GetError(C.bladerf_trim_dac_read(bladeRF.ref, &val))
+	err := operationError(C.bladerf_trim_dac_read(bladeRF.ref, &val), "trim_dac_read")
 
 	if err != nil {
 		return 0, err
@@ -985,16 +1797,16 @@ func (bladeRF *BladeRF) TrimDacRead() (uint16, error) {
 }
 
 func (bladeRF *BladeRF) TrimDacWrite(val uint16) error {
-	return GetError(C.bladerf_trim_dac_write(bladeRF.ref, C.uint16_t(val)))
+	return operationError(C.bladerf_trim_dac_write(bladeRF.ref, C.uint16_t(val)), "trim_dac_write", "val", val)
 }
 
 func (bladeRF *BladeRF) SetTuningMode(mode TuningMode) error {
-	return GetError(C.bladerf_set_tuning_mode(bladeRF.ref, C.bladerf_tuning_mode(mode)))
+	return operationError(C.bladerf_set_tuning_mode(bladeRF.ref, C.bladerf_tuning_mode(mode)), "set_tuning_mode", "mode", mode)
 }
 
 func (bladeRF *BladeRF) GetTuningMode() (TuningMode, error) {
 	var mode C.bladerf_tuning_mode
-	err := GetError(C.bladerf_get_tuning_mode(bladeRF.ref, &mode))
+	err := operationError(C.bladerf_get_tuning_mode(bladeRF.ref, &mode), "get_tuning_mode")
 
 	if err != nil {
 		return 0, err
@@ -1005,7 +1817,7 @@ func (bladeRF *BladeRF) GetTuningMode() (TuningMode, error) {
 
 func (bladeRF *BladeRF) GetTimestamp(direction Direction) (Timestamp, error) {
 	var timestamp C.bladerf_timestamp
-	err := GetError(C.bladerf_get_timestamp(bladeRF.ref, C.bladerf_direction(direction), &timestamp))
+	err := operationError(C.bladerf_get_timestamp(bladeRF.ref, C.bladerf_direction(direction), &timestamp), "get_timestamp", "dir", direction)
 
 	if err != nil {
 		return 0, err
@@ -1016,9 +1828,9 @@ func (bladeRF *BladeRF) GetTimestamp(direction Direction) (Timestamp, error) {
 
 func (bladeRF *BladeRF) ReadTrigger(channel Channel, signal TriggerSignal) (uint8, error) {
 	var val C.uint8_t
-	err := GetError(C.bladerf_read_trigger(
+	err := operationError(C.bladerf_read_trigger(
 		bladeRF.ref,
-		C.bladerf_channel(channel), C.bladerf_trigger_signal(signal), &val))
+		C.bladerf_channel(channel), C.bladerf_trigger_signal(signal), &val), "read_trigger", "ch", channel, "signal", signal)
 
 	if err != nil {
 		return 0, err
@@ -1028,12 +1840,12 @@ func (bladeRF *BladeRF) ReadTrigger(channel Channel, signal TriggerSignal) (uint
 }
 
 func (bladeRF *BladeRF) WriteTrigger(channel Channel, signal TriggerSignal, val uint8) error {
-	return GetError(C.bladerf_write_trigger(bladeRF.ref, C.bladerf_channel(channel), C.bladerf_trigger_signal(signal), C.uint8_t(val)))
+	return operationError(C.bladerf_write_trigger(bladeRF.ref, C.bladerf_channel(channel), C.bladerf_trigger_signal(signal), C.uint8_t(val)), "write_trigger", "ch", channel, "signal", signal, "val", val)
 }
 
 func (bladeRF *BladeRF) ConfigGpioRead() (uint32, error) {
 	var val C.uint32_t
-	err := GetError(C.bladerf_config_gpio_read(bladeRF.ref, &val))
+	err := operationError(C.bladerf_config_gpio_read(bladeRF.ref, &val), "config_gpio_read")
 
 	if err != nil {
 		return 0, err
@@ -1043,25 +1855,63 @@ func (bladeRF *BladeRF) ConfigGpioRead() (uint32, error) {
 }
 
 func (bladeRF *BladeRF) ConfigGpioWrite(val uint32) error {
-	return GetError(C.bladerf_config_gpio_write(bladeRF.ref, C.uint32_t(val)))
+	return operationError(C.bladerf_config_gpio_write(bladeRF.ref, C.uint32_t(val)), "config_gpio_write", "val", val)
+}
+
+func (bladeRF *BladeRF) ExpansionGpioRead() (uint32, error) {
+	var val C.uint32_t
+	err := operationError(C.bladerf_expansion_gpio_read(bladeRF.ref, &val), "expansion_gpio_read")
+
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(val), nil
+}
+
+func (bladeRF *BladeRF) ExpansionGpioWrite(val uint32) error {
+	return operationError(C.bladerf_expansion_gpio_write(bladeRF.ref, C.uint32_t(val)), "expansion_gpio_write", "val", fmt.Sprintf("0x%08x", val))
+}
+
+func (bladeRF *BladeRF) ExpansionGpioMaskedWrite(mask uint32, val uint32) error {
+	return operationError(C.bladerf_expansion_gpio_masked_write(bladeRF.ref, C.uint32_t(mask), C.uint32_t(val)), "expansion_gpio_masked_write", "mask", fmt.Sprintf("0x%08x", mask), "val", fmt.Sprintf("0x%08x", val))
+}
+
+func (bladeRF *BladeRF) ExpansionGpioDirRead() (uint32, error) {
+	var outputs C.uint32_t
+	err := operationError(C.bladerf_expansion_gpio_dir_read(bladeRF.ref, &outputs), "expansion_gpio_dir_read")
+
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(outputs), nil
+}
+
+func (bladeRF *BladeRF) ExpansionGpioDirWrite(outputs uint32) error {
+	return operationError(C.bladerf_expansion_gpio_dir_write(bladeRF.ref, C.uint32_t(outputs)), "expansion_gpio_dir_write", "outputs", fmt.Sprintf("0x%08x", outputs))
+}
+
+func (bladeRF *BladeRF) ExpansionGpioDirMaskedWrite(mask uint32, outputs uint32) error {
+	return operationError(C.bladerf_expansion_gpio_dir_masked_write(bladeRF.ref, C.uint32_t(mask), C.uint32_t(outputs)), "expansion_gpio_dir_masked_write", "mask", fmt.Sprintf("0x%08x", mask), "outputs", fmt.Sprintf("0x%08x", outputs))
 }
 
 func (bladeRF *BladeRF) EraseFlash(eraseBlock uint32, count uint32) error {
-	return GetError(C.bladerf_erase_flash(bladeRF.ref, C.uint32_t(eraseBlock), C.uint32_t(count)))
+	return operationError(C.bladerf_erase_flash(bladeRF.ref, C.uint32_t(eraseBlock), C.uint32_t(count)), "erase_flash", "block", eraseBlock, "count", count)
 }
 
 func (bladeRF *BladeRF) EraseFlashBytes(address uint32, length uint32) error {
-	return GetError(C.bladerf_erase_flash_bytes(bladeRF.ref, C.uint32_t(address), C.uint32_t(length)))
+	return operationError(C.bladerf_erase_flash_bytes(bladeRF.ref, C.uint32_t(address), C.uint32_t(length)), "erase_flash_bytes", "addr", fmt.Sprintf("0x%08x", address), "len", length)
 }
 
 func (bladeRF *BladeRF) LockOtp() error {
-	return GetError(C.bladerf_lock_otp(bladeRF.ref))
+	return operationError(C.bladerf_lock_otp(bladeRF.ref), "lock_otp")
 }
 
 func (bladeRF *BladeRF) ReadFlashBytes(address uint32, bytes uint32) ([]uint8, error) {
 	buf := (*C.uint8_t)(C.malloc((C.size_t)(bytes)))
 	defer C.free(unsafe.Pointer(buf))
-	err := GetError(C.bladerf_read_flash_bytes(bladeRF.ref, buf, C.uint32_t(address), C.uint32_t(bytes)))
+	err := operationError(C.bladerf_read_flash_bytes(bladeRF.ref, buf, C.uint32_t(address), C.uint32_t(bytes)), "read_flash_bytes", "addr", fmt.Sprintf("0x%08x", address), "len", bytes)
 
 	if err != nil {
 		return nil, err
@@ -1085,14 +1935,14 @@ func (bladeRF *BladeRF) WriteFlashBytes(input []uint8, address uint32, bytes uin
 		*addr = (C.uint8_t)(input[i])
 	}
 
-	return GetError(C.bladerf_write_flash_bytes(bladeRF.ref, buf, C.uint32_t(address), C.uint32_t(bytes)))
+	return operationError(C.bladerf_write_flash_bytes(bladeRF.ref, buf, C.uint32_t(address), C.uint32_t(bytes)), "write_flash_bytes", "addr", fmt.Sprintf("0x%08x", address), "len", bytes)
 }
 
 func (bladeRF *BladeRF) ReadOtp() ([]uint8, error) {
 	bytes := uint32(256)
 	buf := (*C.uint8_t)(C.malloc((C.size_t)(bytes)))
 	defer C.free(unsafe.Pointer(buf))
-	err := GetError(C.bladerf_read_otp(bladeRF.ref, buf))
+	err := operationError(C.bladerf_read_otp(bladeRF.ref, buf), "read_otp")
 
 	if err != nil {
 		return nil, err
@@ -1117,14 +1967,14 @@ func (bladeRF *BladeRF) WriteOtp(input []uint8) error {
 		*addr = (C.uint8_t)(input[i])
 	}
 
-	return GetError(C.bladerf_write_otp(bladeRF.ref, buf))
+	return operationError(C.bladerf_write_otp(bladeRF.ref, buf), "write_otp")
 }
 
 func (bladeRF *BladeRF) ReadFlash(page uint32, count uint32) ([]uint8, error) {
 	bytes := uint32(C.sizeof_uint8_t * count * FlashPageSize)
 	buf := (*C.uint8_t)(C.malloc((C.size_t)(bytes)))
 	defer C.free(unsafe.Pointer(buf))
-	err := GetError(C.bladerf_read_flash(bladeRF.ref, buf, C.uint32_t(page), C.uint32_t(count)))
+	err := operationError(C.bladerf_read_flash(bladeRF.ref, buf, C.uint32_t(page), C.uint32_t(count)), "read_flash", "page", page, "count", count)
 
 	if err != nil {
 		return nil, err
@@ -1149,18 +1999,18 @@ func (bladeRF *BladeRF) WriteFlash(input []uint8, page uint32, count uint32) err
 		*addr = (C.uint8_t)(input[i])
 	}
 
-	return GetError(C.bladerf_write_flash(bladeRF.ref, buf, C.uint32_t(page), C.uint32_t(count)))
+	return operationError(C.bladerf_write_flash(bladeRF.ref, buf, C.uint32_t(page), C.uint32_t(count)), "write_flash", "page", page, "count", count)
 }
 
 func (bladeRF *BladeRF) SetRfPort(channel Channel, port string) error {
 	cPort := C.CString(port)
 	defer C.free(unsafe.Pointer(cPort))
-	return GetError(C.bladerf_set_rf_port(bladeRF.ref, C.bladerf_channel(channel), cPort))
+	return operationError(C.bladerf_set_rf_port(bladeRF.ref, C.bladerf_channel(channel), cPort), "set_rf_port", "ch", channel, "port", port)
 }
 
 func (bladeRF *BladeRF) GetRfPort(channel Channel) (string, error) {
 	var portPtr *C.char
-	err := GetError(C.bladerf_get_rf_port(bladeRF.ref, C.bladerf_channel(channel), &portPtr))
+	err := operationError(C.bladerf_get_rf_port(bladeRF.ref, C.bladerf_channel(channel), &portPtr), "get_rf_port", "ch", channel)
 
 	if err != nil {
 		return "", err
@@ -1188,58 +2038,47 @@ func (bladeRF *BladeRF) GetNumberOfRfPorts(channel Channel) (int, error) {
 	countOrCode := C.bladerf_get_rf_ports(bladeRF.ref, C.bladerf_channel(channel), nil, 0)
 
 	if countOrCode < 0 {
-		return 0, GetError(countOrCode)
+		return 0, operationError(countOrCode, "get_rf_ports", "ch", channel)
 	}
 
 	return int(countOrCode), nil
 }
 
 func (bladeRF *BladeRF) GetRfPorts(channel Channel) ([]string, error) {
-	var portPtr *C.char
 	numberOfRfPorts, err := bladeRF.GetNumberOfRfPorts(channel)
 
 	if err != nil {
 		return nil, err
 	}
 
+	if numberOfRfPorts == 0 {
+		return make([]string, 0), nil
+	}
+
+	portPtrs := (**C.char)(C.malloc(C.size_t(uintptr(numberOfRfPorts) * unsafe.Sizeof(uintptr(0)))))
+	defer C.free(unsafe.Pointer(portPtrs))
+
 	countOrCode := C.bladerf_get_rf_ports(
 		bladeRF.ref,
 		C.bladerf_channel(channel),
-		&portPtr,
+		portPtrs,
 		C.uint(numberOfRfPorts),
 	)
 
 	if countOrCode < 0 {
-		return nil, GetError(countOrCode)
-	}
-
-	if countOrCode == 0 {
-		return make([]string, 0), nil
+		return nil, operationError(countOrCode, "get_rf_ports", "ch", channel)
 	}
 
 	count := int(countOrCode)
-	var ports []string
-
-	if count > 0 {
-		offset := uint32(0)
 
-		for i := 0; i < count; i++ {
-			var port []rune
-			finish := false
-
-			for !finish {
-				char := *(*C.char)(unsafe.Pointer(uintptr(unsafe.Pointer(portPtr)) + (C.sizeof_char * uintptr(offset))))
+	if count > numberOfRfPorts {
+		count = numberOfRfPorts
+	}
 
-				if char != 0 {
-					port = append(port, rune(char))
-				} else {
-					finish = true
-					ports = append(ports, string(port))
-				}
+	ports := make([]string, count)
 
-				offset++
-			}
-		}
+	for i, port := range (*[1 << 20]*C.char)(unsafe.Pointer(portPtrs))[:count:count] {
+		ports[i] = C.GoString(port)
 	}
 
 	return ports, nil
@@ -1,7 +1,10 @@
 package bladerf
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	exception "github.com/erayarslan/go-bladerf/error"
 	"testing"
 )
 
@@ -45,14 +48,14 @@ func TestLoadFpga(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
 
 	err = rf.LoadFpga("invalidAddr")
 
-	if err.Error() != "File not found" {
+	if !errors.Is(err, exception.NoFile) {
 		t.Errorf("FAILED cause got %v", err.Error())
 	} else {
 		t.Log("PASSED")
@@ -63,7 +66,7 @@ func TestGetFpgaSize(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -81,7 +84,7 @@ func TestGetQuickTune(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -99,7 +102,7 @@ func TestCancelScheduledReTunes(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -117,7 +120,7 @@ func TestGetFpgaSource(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -135,7 +138,7 @@ func TestGetFpgaBytes(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -153,7 +156,7 @@ func TestGetFpgaFlashSize(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -171,7 +174,7 @@ func TestGetFirmwareVersion(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -207,7 +210,7 @@ func TestIsFpgaConfigured(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -225,7 +228,7 @@ func TestGetDeviceSpeed(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -243,7 +246,7 @@ func TestGetFpgaVersion(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -277,11 +280,21 @@ func TestGetFpgaVersion(t *testing.T) {
 
 func TestFreeDeviceList(t *testing.T) {
 	devices, _ := GetDeviceList()
+
+	if len(devices) == 0 {
+		t.Skip("no device")
+	}
+
 	defer devices[0].FreeDeviceList()
 }
 
 func TestGetDeviceList(t *testing.T) {
 	devices, _ := GetDeviceList()
+
+	if len(devices) == 0 {
+		t.Skip("no device")
+	}
+
 	defer devices[0].FreeDeviceList()
 
 	if len(devices) == 1 {
@@ -315,7 +328,7 @@ func TestGetDeviceInfo(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -333,7 +346,7 @@ func TestDeviceInfoMatches(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -351,7 +364,7 @@ func TestDeviceStringMatches(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -369,7 +382,7 @@ func TestGetDeviceInfoFromString(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -391,17 +404,12 @@ func TestGetDeviceInfoFromString(t *testing.T) {
 
 func TestOpenWithDeviceInfo(t *testing.T) {
 	devices, err := GetDeviceList()
-	defer devices[0].FreeDeviceList()
 
-	if err != nil {
-		t.Error(err)
-		t.FailNow()
+	if err != nil || len(devices) == 0 {
+		t.Skip("no device")
 	}
 
-	if len(devices) == 0 {
-		t.Error("Device cannot be found")
-		t.FailNow()
-	}
+	defer devices[0].FreeDeviceList()
 
 	rf, err := devices[0].Open()
 	defer rf.Close()
@@ -415,17 +423,12 @@ func TestOpenWithDeviceInfo(t *testing.T) {
 
 func TestOpenWithDeviceIdentifier(t *testing.T) {
 	devices, err := GetDeviceList()
-	defer devices[0].FreeDeviceList()
 
-	if err != nil {
-		t.Error(err)
-		t.FailNow()
+	if err != nil || len(devices) == 0 {
+		t.Skip("no device")
 	}
 
-	if len(devices) == 0 {
-		t.Error("Device cannot be found")
-		t.FailNow()
-	}
+	defer devices[0].FreeDeviceList()
 
 	rf, err := OpenWithDeviceIdentifier("*:serial=" + devices[0].Serial)
 	defer rf.Close()
@@ -441,7 +444,7 @@ func TestOpen(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -453,7 +456,7 @@ func TestClose(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	rf.Close()
@@ -465,7 +468,7 @@ func TestSetLoopback(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -483,7 +486,7 @@ func TestIsLoopbackModeSupported(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -501,7 +504,7 @@ func TestGetLoopback(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -519,7 +522,7 @@ func TestScheduleReTune(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -538,7 +541,7 @@ func TestSelectBand(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -556,7 +559,7 @@ func TestSetFrequency(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -574,7 +577,7 @@ func TestGetFrequency(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -593,7 +596,7 @@ func TestSetSampleRate(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -611,7 +614,7 @@ func TestSetRxMux(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -629,7 +632,7 @@ func TestGetRxMux(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -647,7 +650,7 @@ func TestSetRationalSampleRate(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -666,7 +669,7 @@ func TestGetSampleRate(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -685,7 +688,7 @@ func TestGetRationalSampleRate(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -703,7 +706,7 @@ func TestGetSampleRateRange(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -721,7 +724,7 @@ func TestGetFrequencyRange(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -739,7 +742,7 @@ func TestSetBandwidth(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -757,7 +760,7 @@ func TestGetBandwidth(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -776,7 +779,7 @@ func TestGetBandwidthRange(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -794,7 +797,7 @@ func TestSetGain(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -814,7 +817,7 @@ func TestGetGain(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -835,7 +838,7 @@ func TestGetGainStage(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -853,7 +856,7 @@ func TestGetGainMode(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -872,7 +875,7 @@ func TestSetGainStage(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -893,7 +896,7 @@ func TestGetGainStageRange(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -911,7 +914,7 @@ func TestGetGainRange(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -929,7 +932,7 @@ func TestGetNumberOfGainStages(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -947,7 +950,7 @@ func TestSetCorrection(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -968,7 +971,7 @@ func TestGetCorrection(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1005,7 +1008,7 @@ func TestGetBoardName(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1028,7 +1031,7 @@ func TestGetSerial(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1046,7 +1049,7 @@ func TestGetSerialStruct(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1064,7 +1067,7 @@ func TestGetGainStages(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1082,7 +1085,7 @@ func TestGetGainModes(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1103,7 +1106,7 @@ func TestGetLoopbackModes(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1124,7 +1127,7 @@ func TestSetGainMode(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1143,7 +1146,7 @@ func TestEnableModule(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1161,7 +1164,7 @@ func TestDisableModule(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1179,7 +1182,7 @@ func TestTrigger(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1202,15 +1205,10 @@ func TestTrigger(t *testing.T) {
 }
 
 func TestSyncTX(t *testing.T) {
-	rf, err := Open()
-
-	if err != nil {
-		t.Error(err)
-	}
-
+	var rf Device = NewSimulator()
 	defer rf.Close()
 
-	err = rf.SyncConfig(TxX1, FormatSc16Q11, 2, 1024, 1, 3500)
+	err := rf.SyncConfig(TxX1, FormatSc16Q11, 2, 1024, 1, 3500)
 	err = rf.EnableModule(ChannelTx(0))
 
 	data := make([]complex64, 4)
@@ -1229,38 +1227,168 @@ func TestSyncTX(t *testing.T) {
 }
 
 func TestSyncRX(t *testing.T) {
-	rf, err := Open()
+	var rf Device = NewSimulator()
+	defer rf.Close()
 
-	if err != nil {
-		t.Error(err)
+	err := rf.SyncConfig(RxX1, FormatSc16Q11, 2, 1024, 1, 3500)
+	err = rf.EnableModule(ChannelRx(0))
+
+	data, _, err := rf.SyncRX(1024, Metadata{}, 3500)
+
+	complexData := Int16ToComplex64(data)
+
+	if err == nil && len(data) == 2048 && len(complexData) == 1024 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err.Error())
 	}
+}
 
+func TestSyncRXInto(t *testing.T) {
+	var rf Device = NewSimulator()
 	defer rf.Close()
 
-	err = rf.SyncConfig(RxX1, FormatSc16Q11, 2, 1024, 1, 3500)
+	err := rf.SyncConfig(RxX1, FormatSc16Q11, 2, 1024, 1, 3500)
 	err = rf.EnableModule(ChannelRx(0))
 
-	data, _, err := rf.SyncRX(1024, Metadata{}, 3500)
+	buffer := NewSampleBuffer(1024)
+	defer buffer.Free()
 
-	complexData := Int16ToComplex64(data)
+	count, _, err := rf.SyncRXInto(buffer.Samples, Metadata{}, 3500)
 
-	if err == nil && len(data) == 1024 && len(complexData) == 512 {
+	if err == nil && count == 1024 {
 		t.Log("PASSED")
 	} else {
 		t.Errorf("FAILED cause got %v", err.Error())
 	}
 }
 
-func TestAsyncRX(t *testing.T) {
+func TestSyncRX8(t *testing.T) {
+	var rf Device = NewSimulator()
+	defer rf.Close()
+
+	err := rf.SyncConfig(RxX1, FormatSc8Q7, 2, 1024, 1, 3500)
+	err = rf.EnableModule(ChannelRx(0))
+
+	data, _, err := rf.SyncRX8(1024, Metadata{}, 3500)
+
+	complexData := Sc8Q7ToComplex64(data)
+
+	if err == nil && len(data) == 2048 && len(complexData) == 1024 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err.Error())
+	}
+
+	if _, _, err = rf.SyncRX(1024, Metadata{}, 3500); err != nil {
+		t.Log("PASSED")
+	} else {
+		t.Error("FAILED cause 16-bit buffer accepted for SC8_Q7")
+	}
+}
+
+func benchmarkDevice(b *testing.B, layout ChannelLayout, channel Channel) BladeRF {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		b.Skip(err)
 	}
 
+	_ = rf.SyncConfig(layout, FormatSc16Q11, 16, 8192, 8, 3500)
+	_ = rf.EnableModule(channel)
+
+	return rf
+}
+
+func BenchmarkSyncRX(b *testing.B) {
+	rf := benchmarkDevice(b, RxX1, ChannelRx(0))
 	defer rf.Close()
 
-	err = rf.EnableModule(ChannelRx(0))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		_, _, _ = rf.SyncRX(8192, Metadata{}, 3500)
+	}
+}
+
+func BenchmarkSyncRXInto(b *testing.B) {
+	rf := benchmarkDevice(b, RxX1, ChannelRx(0))
+	defer rf.Close()
+
+	buf := make([]int16, 8192*2)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		_, _, _ = rf.SyncRXInto(buf, Metadata{}, 3500)
+	}
+}
+
+func BenchmarkSyncTX(b *testing.B) {
+	rf := benchmarkDevice(b, TxX1, ChannelTx(0))
+	defer rf.Close()
+
+	buf := make([]int16, 8192*2)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		_, _ = rf.SyncTX(buf, Metadata{}, 3500)
+	}
+}
+
+func BenchmarkSyncTXFrom(b *testing.B) {
+	rf := benchmarkDevice(b, TxX1, ChannelTx(0))
+	defer rf.Close()
+
+	buffer := NewSampleBuffer(8192)
+	defer buffer.Free()
+
+	metadata := NewMetadata(0, 0)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		_, _ = rf.SyncTXFrom(buffer.Samples, metadata, 3500)
+	}
+}
+
+// The sample buffer benchmarks need no board. They compare reading a
+// C-allocated buffer one append at a time, as SyncRX used to, with a single
+// bulk copy into a pooled slice.
+func BenchmarkSampleBufferAppend(b *testing.B) {
+	buffer := NewSampleBuffer(8192)
+	defer buffer.Free()
+
+	b.SetBytes(int64(len(buffer.Samples) * 2))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		var results []int16
+
+		for _, sample := range buffer.Samples {
+			results = append(results, sample)
+		}
+	}
+}
+
+func BenchmarkSampleBufferCopy(b *testing.B) {
+	buffer := NewSampleBuffer(8192)
+	defer buffer.Free()
+
+	pool := NewSampleBufferPool(8192)
+	b.SetBytes(int64(len(buffer.Samples) * 2))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		results := pool.Get()
+		copy(results, buffer.Samples)
+		pool.Put(results)
+	}
+}
+
+func TestAsyncRX(t *testing.T) {
+	var rf Device = NewSimulator()
+	defer rf.Close()
+
+	err := rf.EnableModule(ChannelRx(0))
 
 	rxStream, err := rf.InitStream(
 		FormatSc16Q11,
@@ -1270,7 +1398,7 @@ func TestAsyncRX(t *testing.T) {
 		func(data []int16) GoStream {
 			complexData := Int16ToComplex64(data)
 
-			if len(complexData) == 512 {
+			if len(complexData) == 1024 {
 				t.Log("PASSED")
 			} else {
 				t.Errorf("FAILED cause got %v", len(complexData))
@@ -1285,16 +1413,46 @@ func TestAsyncRX(t *testing.T) {
 	}
 }
 
-func TestDeInitAsyncRX(t *testing.T) {
-	rf, err := Open()
+func TestStartRX(t *testing.T) {
+	var rf Device = NewSimulator()
+	defer rf.Close()
+
+	err := rf.EnableModule(ChannelRx(0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	blocks, err := rf.StartRX(ctx, StreamConfig{
+		Layout:           RxX1,
+		Format:           FormatSc16Q11,
+		NumBuffers:       16,
+		SamplesPerBuffer: 1024,
+		NumTransfers:     8,
+	})
 
 	if err != nil {
-		t.Error(err)
+		t.Errorf("FAILED cause got %v", err.Error())
+		return
 	}
 
+	block := <-blocks
+	cancel()
+
+	for range blocks {
+	}
+
+	if block.Err == nil && len(block.Samples) == 2048 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", block.Err)
+	}
+}
+
+func TestDeInitAsyncRX(t *testing.T) {
+	var rf Device = NewSimulator()
 	defer rf.Close()
 
-	err = rf.EnableModule(ChannelRx(0))
+	_ = rf.EnableModule(ChannelRx(0))
 
 	rxStream, err := rf.InitStream(
 		FormatSc16Q11,
@@ -1305,15 +1463,93 @@ func TestDeInitAsyncRX(t *testing.T) {
 			return GoStreamShutdown
 		})
 
+	if err != nil {
+		t.Errorf("FAILED cause got %v", err.Error())
+		return
+	}
+
 	rxStream.DeInit()
 	t.Log("PASSED")
 }
 
+func TestAsyncTX(t *testing.T) {
+	var rf Device = NewSimulator()
+	defer rf.Close()
+
+	err := rf.EnableModule(ChannelTx(0))
+	count := 0
+
+	txStream, err := rf.InitStream(
+		FormatSc16Q11,
+		16,
+		1024,
+		8,
+		func(data []int16) GoStream {
+			copy(data, Complex64ToInt16(make([]complex64, len(data)/2)))
+			count++
+
+			if count > 64 {
+				return GoStreamShutdown
+			}
+
+			return GoStreamNext
+		})
+
+	err = txStream.Start(TxX1)
+	txStream.DeInit()
+
+	if err == nil {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err.Error())
+	}
+}
+
+func TestSubmitStreamBuffer(t *testing.T) {
+	var rf Device = NewSimulator()
+	defer rf.Close()
+
+	err := rf.EnableModule(ChannelTx(0))
+
+	txStream, err := rf.InitStream(
+		FormatSc16Q11,
+		16,
+		1024,
+		8,
+		func(data []int16) GoStream {
+			return GoStreamNoData
+		})
+
+	done := make(chan error)
+
+	go func() {
+		done <- txStream.Start(TxX1)
+	}()
+
+	for _, buffer := range txStream.Buffers() {
+		err = txStream.SubmitBuffer(buffer, 3500)
+
+		if err != nil {
+			break
+		}
+	}
+
+	if err == nil {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err.Error())
+	}
+
+	_ = rf.DisableModule(ChannelTx(0))
+	<-done
+	txStream.DeInit()
+}
+
 func TestGetStreamTimeout(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1328,15 +1564,10 @@ func TestGetStreamTimeout(t *testing.T) {
 }
 
 func TestSetStreamTimeout(t *testing.T) {
-	rf, err := Open()
-
-	if err != nil {
-		t.Error(err)
-	}
-
+	var rf Device = NewSimulator()
 	defer rf.Close()
 
-	err = rf.SetStreamTimeout(Rx, 3000)
+	err := rf.SetStreamTimeout(Rx, 3000)
 	timeout, err := rf.GetStreamTimeout(Rx)
 
 	if err == nil && timeout == 3000 {
@@ -1350,7 +1581,7 @@ func TestAttachExpansionBoard(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1368,7 +1599,7 @@ func TestGetAttachedExpansionBoard(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1386,7 +1617,7 @@ func TestSetVctcxoTamerMode(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1404,7 +1635,7 @@ func TestGetVctcxoTamerMode(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1422,7 +1653,7 @@ func TestGetVctcxoTrim(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1440,7 +1671,7 @@ func TestTrimDacRead(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1458,7 +1689,7 @@ func TestTrimDacWrite(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1477,7 +1708,7 @@ func TestSetTuningMode(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1496,7 +1727,7 @@ func TestGetTuningMode(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1515,7 +1746,7 @@ func TestGetTimestamp(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1533,7 +1764,7 @@ func TestReadTrigger(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1552,7 +1783,7 @@ func TestWriteTrigger(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1571,7 +1802,7 @@ func TestConfigGpioRead(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1589,7 +1820,7 @@ func TestConfigGpioWrite(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1608,7 +1839,7 @@ func TestEraseFlash(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1626,7 +1857,7 @@ func TestEraseFlashBytes(t *testing.T) {
 	rf, err := Open()
 
 	if err != nil {
-		t.Error(err)
+		t.Skip(err)
 	}
 
 	defer rf.Close()
@@ -1665,8 +1896,7 @@ func TestReadFlashBytes(t *testing.T) {
 	devices, _ := GetDeviceList()
 
 	if len(devices) == 0 {
-		fmt.Println("NO DEVICE")
-		return
+		t.Skip("no device")
 	}
 
 	rf, _ := devices[0].Open()
@@ -1686,8 +1916,7 @@ func TestReadFlash(t *testing.T) {
 	devices, _ := GetDeviceList()
 
 	if len(devices) == 0 {
-		fmt.Println("NO DEVICE")
-		return
+		t.Skip("no device")
 	}
 
 	rf, _ := devices[0].Open()
@@ -1707,8 +1936,7 @@ func TestRfPort(t *testing.T) {
 	devices, _ := GetDeviceList()
 
 	if len(devices) == 0 {
-		fmt.Println("NO DEVICE")
-		return
+		t.Skip("no device")
 	}
 
 	rf, _ := devices[0].Open()
@@ -1729,8 +1957,7 @@ func TestReadOtp(t *testing.T) {
 	devices, _ := GetDeviceList()
 
 	if len(devices) == 0 {
-		fmt.Println("NO DEVICE")
-		return
+		t.Skip("no device")
 	}
 
 	rf, _ := devices[0].Open()
@@ -1755,3 +1982,32 @@ func TestChannel(t *testing.T) {
 	fmt.Println(ChannelIsTx(2))
 	fmt.Println(ChannelIsTx(3))
 }
+
+func TestRfic(t *testing.T) {
+	devices, _ := GetDeviceList()
+
+	if len(devices) == 0 {
+		t.Skip("no device")
+	}
+
+	rf, _ := devices[0].Open()
+	defer rf.Close()
+
+	temperature, err := rf.GetRficTemperature()
+
+	if rf.GetBoardName() == "bladerf1" {
+		if errors.Is(err, exception.Unsupported) {
+			t.Log("PASSED")
+		} else {
+			t.Errorf("FAILED cause got %v", err)
+		}
+
+		return
+	}
+
+	fmt.Println(temperature)
+	pre, sym, _ := rf.GetRficRssi(Rx1Channel)
+	fmt.Println(pre, sym)
+	fir, _ := rf.GetRficRxFir()
+	fmt.Println(fir)
+}
@@ -0,0 +1,179 @@
+package bladerf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	exception "github.com/erayarslan/go-bladerf/error"
+	"time"
+)
+
+const burstTimeout = 1000
+
+// Burst is a payload of interleaved SC16 Q11 samples to be sent starting at
+// the TX timestamp At.
+type Burst struct {
+	Samples []int16
+	At      Timestamp
+}
+
+// LateBurstError is returned when the device reports that the start of a
+// burst was already in the past. Now is the TX timestamp read right after
+// the failure and Lateness the time between At and Now.
+type LateBurstError struct {
+	At       Timestamp
+	Now      Timestamp
+	Lateness time.Duration
+	Err      error
+}
+
+func (e *LateBurstError) Error() string {
+	return fmt.Sprintf("burst at %d is %v late (now %d): %v", e.At, e.Lateness, e.Now, e.Err)
+}
+
+func (e *LateBurstError) Unwrap() error {
+	return e.Err
+}
+
+// burstChunks splits samples into buffers of bufferSize samples. The last
+// buffer is zero padded to full size and the burst always ends on at least
+// one zero sample, so the DAC is left at zero once the burst ends. A payload
+// with an unpaired I or Q value is rejected.
+func burstChunks(samples []int16, bufferSize uint) ([][]int16, error) {
+	if len(samples)%2 != 0 {
+		return nil, exception.NewWithOperation(int(exception.Inval), "transmit_burst", formatArguments("values", len(samples)))
+	}
+
+	size := 2 * int(bufferSize)
+	length := len(samples)
+	tail := length % size
+	full := length - tail
+
+	if tail == 0 && length > 0 && samples[length-2] == 0 && samples[length-1] == 0 {
+		full -= size
+	}
+
+	chunks := make([][]int16, 0, full/size+1)
+
+	for offset := 0; offset < full; offset += size {
+		chunks = append(chunks, samples[offset:offset+size])
+	}
+
+	last := make([]int16, size)
+	copy(last, samples[full:length])
+
+	return append(chunks, last), nil
+}
+
+func burstTimeoutFor(ctx context.Context) uint {
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining := time.Until(deadline) / time.Millisecond
+
+		if remaining < 1 {
+			return 1
+		} else if remaining < burstTimeout {
+			return uint(remaining)
+		}
+	}
+
+	return burstTimeout
+}
+
+func lateBurst(device Device, at Timestamp, err error) error {
+	lateError := &LateBurstError{At: at, Err: err}
+	now, timestampErr := device.GetTimestamp(Tx)
+
+	if timestampErr != nil {
+		return lateError
+	}
+
+	lateError.Now = now
+
+	if rate, rateErr := device.GetRationalSampleRate(ChannelTx(0)); rateErr == nil && now > at {
+		lateError.Lateness = ticksToDuration(now-at, rate)
+	}
+
+	return lateError
+}
+
+// transmitBurst sends samples as one burst: the first buffer carries
+// MetaFlagTxBurstStart and the timestamp, the last MetaFlagTxBurstEnd. If
+// ctx ends part way, the burst is closed with a buffer of zeros.
+func transmitBurst(ctx context.Context, device Device, format Format, bufferSize uint, samples []int16, at Timestamp) error {
+	if format != FormatSc16Q11Meta || bufferSize == 0 {
+		return exception.NewWithOperation(int(exception.Inval), "transmit_burst", formatArguments("format", format, "size", bufferSize))
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	chunks, err := burstChunks(samples, bufferSize)
+
+	if err != nil {
+		return err
+	}
+
+	for i, chunk := range chunks {
+		var flags uint32
+		var timestamp Timestamp
+
+		if i == 0 {
+			flags, timestamp = MetaFlagTxBurstStart, at
+		} else if err := ctx.Err(); err != nil {
+			end := make([]int16, 2*bufferSize)
+			_, _ = device.SyncTX(end, NewMetadata(0, MetaFlagTxBurstEnd), burstTimeout)
+			return err
+		}
+
+		if i == len(chunks)-1 {
+			flags |= MetaFlagTxBurstEnd
+		}
+
+		_, err := device.SyncTX(chunk, NewMetadata(timestamp, flags), burstTimeoutFor(ctx))
+
+		if err != nil {
+			if i == 0 && errors.Is(err, exception.TimePast) {
+				return lateBurst(device, at, err)
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func transmitBursts(ctx context.Context, device Device, format Format, bufferSize uint, bursts []Burst) (int, error) {
+	for i, burst := range bursts {
+		if err := transmitBurst(ctx, device, format, bufferSize, burst.Samples, burst.At); err != nil {
+			return i, err
+		}
+	}
+
+	return len(bursts), nil
+}
+
+// TransmitBurst sends samples as a single burst starting at the TX
+// timestamp at, split into buffers of the size passed to SyncConfig. TX must
+// be configured for FormatSc16Q11Meta. A start time already in the past is
+// reported as a *LateBurstError.
+func (bladeRF *BladeRF) TransmitBurst(ctx context.Context, samples []int16, at Timestamp) error {
+	return transmitBurst(ctx, bladeRF, bladeRF.syncFormats[Tx], bladeRF.syncBufferSizes[Tx], samples, at)
+}
+
+// TransmitBursts sends the bursts in order and returns how many were sent
+// before the first error.
+func (bladeRF *BladeRF) TransmitBursts(ctx context.Context, bursts []Burst) (int, error) {
+	return transmitBursts(ctx, bladeRF, bladeRF.syncFormats[Tx], bladeRF.syncBufferSizes[Tx], bursts)
+}
+
+func (simulator *Simulator) TransmitBurst(ctx context.Context, samples []int16, at Timestamp) error {
+	format, bufferSize := simulator.syncSettings(Tx)
+	return transmitBurst(ctx, simulator, format, bufferSize, samples, at)
+}
+
+func (simulator *Simulator) TransmitBursts(ctx context.Context, bursts []Burst) (int, error) {
+	format, bufferSize := simulator.syncSettings(Tx)
+	return transmitBursts(ctx, simulator, format, bufferSize, bursts)
+}
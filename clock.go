@@ -0,0 +1,334 @@
+package bladerf
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+)
+
+const clockObservations = 64
+
+// clockSegment is a span of timestamps counted at one sample rate. elapsed
+// is the device time at start.
+type clockSegment struct {
+	start   Timestamp
+	elapsed time.Duration
+	rate    RationalRate
+}
+
+type clockObservation struct {
+	elapsed time.Duration
+	host    time.Time
+}
+
+// Clock converts between the timestamps of a channel, durations of device
+// time and host wall-clock time. Device time is counted from timestamp zero
+// at the rate in effect when the Clock was created, and follows later rate
+// changes reported through UpdateRate. Host time is anchored by Sync, which
+// also refines the estimated offset and drift of the sample clock.
+type Clock struct {
+	device    Device
+	channel   Channel
+	direction Direction
+	now       func() time.Time
+
+	mu           sync.Mutex
+	segments     []clockSegment
+	observations []clockObservation
+	epoch        time.Time
+	scale        float64
+}
+
+// rateFraction returns the sample rate as an exact fraction of samples per
+// second.
+func rateFraction(rate RationalRate) (*big.Int, *big.Int) {
+	num, den := rate.Num, rate.Den
+
+	if den == 0 {
+		num, den = 0, 1
+	}
+
+	samples := new(big.Int).SetUint64(rate.Integer)
+	samples.Mul(samples, new(big.Int).SetUint64(den))
+	samples.Add(samples, new(big.Int).SetUint64(num))
+
+	return samples, new(big.Int).SetUint64(den)
+}
+
+// roundDiv divides two non-negative integers, rounding half up.
+func roundDiv(x *big.Int, y *big.Int) *big.Int {
+	twice := new(big.Int).Lsh(y, 1)
+	result := new(big.Int).Lsh(x, 1)
+	result.Add(result, y)
+	return result.Quo(result, twice)
+}
+
+func ticksToDuration(ticks Timestamp, rate RationalRate) time.Duration {
+	samples, den := rateFraction(rate)
+
+	if samples.Sign() == 0 {
+		return 0
+	}
+
+	nanoseconds := new(big.Int).SetUint64(uint64(ticks))
+	nanoseconds.Mul(nanoseconds, den)
+	nanoseconds.Mul(nanoseconds, big.NewInt(int64(time.Second)))
+
+	return time.Duration(roundDiv(nanoseconds, samples).Int64())
+}
+
+func durationToTicks(duration time.Duration, rate RationalRate) Timestamp {
+	if duration <= 0 {
+		return 0
+	}
+
+	samples, den := rateFraction(rate)
+	ticks := new(big.Int).Mul(big.NewInt(int64(duration)), samples)
+
+	return Timestamp(roundDiv(ticks, den.Mul(den, big.NewInt(int64(time.Second)))).Uint64())
+}
+
+func sameRate(a RationalRate, b RationalRate) bool {
+	return a.Integer == b.Integer && a.Num == b.Num && a.Den == b.Den
+}
+
+// NewClock reads the sample rate of channel and takes a first host/device
+// correlation.
+func NewClock(device Device, channel Channel) (*Clock, error) {
+	rate, err := device.GetRationalSampleRate(channel)
+
+	if err != nil {
+		return nil, err
+	}
+
+	clock := &Clock{
+		device:    device,
+		channel:   channel,
+		direction: Rx,
+		now:       time.Now,
+		segments:  []clockSegment{{rate: rate}},
+		scale:     1,
+	}
+
+	if ChannelIsTx(int(channel)) {
+		clock.direction = Tx
+	}
+
+	if err = clock.Sync(); err != nil {
+		return nil, err
+	}
+
+	return clock, nil
+}
+
+// Sync reads the device timestamp between two reads of the host clock and
+// records the midpoint as an observation. Offset and drift are refitted by
+// least squares over the most recent observations.
+func (clock *Clock) Sync() error {
+	before := clock.now()
+	timestamp, err := clock.device.GetTimestamp(clock.direction)
+	after := clock.now()
+
+	if err != nil {
+		return err
+	}
+
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+
+	clock.observations = append(clock.observations, clockObservation{
+		elapsed: clock.toDuration(timestamp),
+		host:    before.Add(after.Sub(before) / 2),
+	})
+
+	if len(clock.observations) > clockObservations {
+		clock.observations = clock.observations[1:]
+	}
+
+	clock.fit()
+	return nil
+}
+
+func (clock *Clock) fit() {
+	first := clock.observations[0].host
+	count := float64(len(clock.observations))
+
+	var meanX, meanY float64
+
+	for _, observation := range clock.observations {
+		meanX += observation.elapsed.Seconds() / count
+		meanY += observation.host.Sub(first).Seconds() / count
+	}
+
+	var sxx, sxy float64
+
+	for _, observation := range clock.observations {
+		x := observation.elapsed.Seconds() - meanX
+		sxx += x * x
+		sxy += x * (observation.host.Sub(first).Seconds() - meanY)
+	}
+
+	clock.scale = 1
+
+	if sxx > 0 {
+		clock.scale = sxy / sxx
+	}
+
+	intercept := meanY - clock.scale*meanX
+	clock.epoch = first.Add(time.Duration(intercept * float64(time.Second)))
+}
+
+// UpdateRate rereads the sample rate and, if it changed, starts counting
+// device time at the new rate from the current timestamp. Call it right
+// after changing the sample rate of the channel.
+func (clock *Clock) UpdateRate() error {
+	rate, err := clock.device.GetRationalSampleRate(clock.channel)
+
+	if err != nil {
+		return err
+	}
+
+	timestamp, err := clock.device.GetTimestamp(clock.direction)
+
+	if err != nil {
+		return err
+	}
+
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+
+	last := clock.segments[len(clock.segments)-1]
+
+	if sameRate(rate, last.rate) {
+		return nil
+	}
+
+	if timestamp < last.start {
+		timestamp = last.start
+	}
+
+	clock.segments = append(clock.segments, clockSegment{
+		start:   timestamp,
+		elapsed: last.elapsed + ticksToDuration(timestamp-last.start, last.rate),
+		rate:    rate,
+	})
+
+	return nil
+}
+
+func (clock *Clock) Rate() RationalRate {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+
+	return clock.segments[len(clock.segments)-1].rate
+}
+
+// Epoch is the estimated host time at which the device timestamp was zero.
+func (clock *Clock) Epoch() time.Time {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+
+	return clock.epoch
+}
+
+// Drift is the estimated fractional error of the sample clock against the
+// host clock; positive when the device counts fast. It is zero until two
+// Syncs far enough apart have been made.
+func (clock *Clock) Drift() float64 {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+
+	return 1/clock.scale - 1
+}
+
+func (clock *Clock) segment(timestamp Timestamp) int {
+	return sort.Search(len(clock.segments), func(i int) bool {
+		return clock.segments[i].start > timestamp
+	}) - 1
+}
+
+func (clock *Clock) toDuration(timestamp Timestamp) time.Duration {
+	segment := clock.segments[clock.segment(timestamp)]
+	return segment.elapsed + ticksToDuration(timestamp-segment.start, segment.rate)
+}
+
+func (clock *Clock) fromDuration(duration time.Duration) Timestamp {
+	index := sort.Search(len(clock.segments), func(i int) bool {
+		return clock.segments[i].elapsed > duration
+	}) - 1
+
+	if index < 0 {
+		return 0
+	}
+
+	segment := clock.segments[index]
+	return segment.start + durationToTicks(duration-segment.elapsed, segment.rate)
+}
+
+// ToDuration returns the device time elapsed at timestamp.
+func (clock *Clock) ToDuration(timestamp Timestamp) time.Duration {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+
+	return clock.toDuration(timestamp)
+}
+
+// FromDuration returns the timestamp, rounded to the nearest sample, at
+// which duration of device time has elapsed.
+func (clock *Clock) FromDuration(duration time.Duration) Timestamp {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+
+	return clock.fromDuration(duration)
+}
+
+// Add returns the timestamp duration after timestamp, across any rate
+// changes in between. duration may be negative.
+func (clock *Clock) Add(timestamp Timestamp, duration time.Duration) Timestamp {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+
+	return clock.fromDuration(clock.toDuration(timestamp) + duration)
+}
+
+// Sub returns the device time between two timestamps. It is exact to the
+// nanosecond when no rate change lies between them.
+func (clock *Clock) Sub(a Timestamp, b Timestamp) time.Duration {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+
+	if index := clock.segment(a); index == clock.segment(b) {
+		if a < b {
+			return -ticksToDuration(b-a, clock.segments[index].rate)
+		}
+
+		return ticksToDuration(a-b, clock.segments[index].rate)
+	}
+
+	return clock.toDuration(a) - clock.toDuration(b)
+}
+
+// ToTime returns the estimated host time of timestamp.
+func (clock *Clock) ToTime(timestamp Timestamp) time.Time {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+
+	elapsed := clock.toDuration(timestamp)
+	return clock.epoch.Add(elapsed + time.Duration(float64(elapsed)*(clock.scale-1)))
+}
+
+// FromTime returns the timestamp the device is estimated to reach at host
+// time t.
+func (clock *Clock) FromTime(t time.Time) Timestamp {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+
+	host := t.Sub(clock.epoch)
+	return clock.fromDuration(host + time.Duration(float64(host)*(1/clock.scale-1)))
+}
+
+// Now returns the current device timestamp.
+func (clock *Clock) Now() (Timestamp, error) {
+	return clock.device.GetTimestamp(clock.direction)
+}
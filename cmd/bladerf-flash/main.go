@@ -0,0 +1,276 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"github.com/erayarslan/go-bladerf"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const usage = `usage: bladerf-flash [flags] <command> [image]
+
+commands:
+  firmware <image.img>   write FX3 firmware to flash
+  fpga <image.rbf>       write an FPGA bitstream to flash for autoloading
+  erase-fpga             erase the autoloaded FPGA bitstream
+  reset                  reset the board
+  bootloaders            list devices waiting in the FX3 bootloader
+  recover <image.img>    boot a bootloader device with firmware in RAM
+
+flags:
+`
+
+var (
+	deviceIdentifier = flag.String("d", "", "device identifier string (devstr)")
+	bootloader       = flag.Int("b", 0, "index into the bootloader list for recover")
+	assumeYes        = flag.Bool("y", false, "do not ask for confirmation")
+	dryRun           = flag.Bool("n", false, "print what would be done without touching the device")
+)
+
+var extensions = map[string][]string{
+	"firmware": {".img"},
+	"fpga":     {".rbf"},
+	"recover":  {".img"},
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "bladerf-flash:", err)
+	os.Exit(1)
+}
+
+func checkImage(command string, path string) error {
+	info, err := os.Stat(path)
+
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() || info.Size() == 0 {
+		return fmt.Errorf("%s is not a usable image", path)
+	}
+
+	extension := strings.ToLower(filepath.Ext(path))
+
+	for _, allowed := range extensions[command] {
+		if extension == allowed {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s: expected a %s file for %s", path, strings.Join(extensions[command], " or "), command)
+}
+
+// confirm asks the user to type "yes" before a step that rewrites flash or
+// drops the device off the bus.
+func confirm(action string) bool {
+	if *assumeYes {
+		return true
+	}
+
+	fmt.Printf("%s\nType \"yes\" to continue: ", action)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+
+	return strings.TrimSpace(answer) == "yes"
+}
+
+// step runs a blocking libbladeRF call and reports elapsed time while it is
+// in progress, since flashing gives no progress callback.
+func step(description string, call func() error) error {
+	fmt.Printf("%s...", description)
+
+	if *dryRun {
+		fmt.Println(" skipped (dry run)")
+		return nil
+	}
+
+	done := make(chan error, 1)
+	start := time.Now()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	go func() {
+		done <- call()
+	}()
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				fmt.Printf(" failed after %s\n", time.Since(start).Round(time.Second))
+				return err
+			}
+
+			fmt.Printf(" done in %s\n", time.Since(start).Round(time.Millisecond))
+			return nil
+		case <-ticker.C:
+			fmt.Printf("\r%s... %s", description, time.Since(start).Round(time.Second))
+		}
+	}
+}
+
+func open() (bladerf.BladeRF, error) {
+	if *deviceIdentifier == "" {
+		return bladerf.Open()
+	}
+
+	return bladerf.OpenWithDeviceIdentifier(*deviceIdentifier)
+}
+
+func withDevice(action string, steps func(rf *bladerf.BladeRF) error) error {
+	rf, err := open()
+
+	if err != nil {
+		return err
+	}
+
+	serial, err := rf.GetSerialStruct()
+
+	if err != nil {
+		rf.Close()
+		return err
+	}
+
+	if !confirm(fmt.Sprintf("About to %s on %s (serial %s).", action, rf.GetBoardName(), serial.Serial)) {
+		rf.Close()
+		return fmt.Errorf("aborted")
+	}
+
+	err = steps(&rf)
+	rf.Close()
+	return err
+}
+
+func reset(rf *bladerf.BladeRF) error {
+	err := step("Resetting device", rf.DeviceReset)
+
+	if err == nil && !*dryRun {
+		fmt.Println("The device will re-enumerate; reopen it before further use.")
+	}
+
+	return err
+}
+
+func listBootloaders() error {
+	bootloaders, err := bladerf.GetBootloaderList()
+
+	if err != nil {
+		return err
+	}
+
+	if len(bootloaders) > 0 {
+		defer bootloaders[0].FreeDeviceList()
+	}
+
+	for i, info := range bootloaders {
+		fmt.Printf("[%d] bus %d address %d serial %s\n", i, info.UsbBus, info.UsbAddr, info.Serial)
+	}
+
+	if len(bootloaders) == 0 {
+		fmt.Println("No devices in bootloader mode.")
+	}
+
+	return nil
+}
+
+func recoverDevice(imagePath string) error {
+	bootloaders, err := bladerf.GetBootloaderList()
+
+	if err != nil {
+		return err
+	}
+
+	if len(bootloaders) == 0 {
+		return fmt.Errorf("no devices in bootloader mode")
+	}
+
+	defer bootloaders[0].FreeDeviceList()
+
+	if *bootloader < 0 || *bootloader >= len(bootloaders) {
+		return fmt.Errorf("bootloader index %d out of range, %d found", *bootloader, len(bootloaders))
+	}
+
+	info := bootloaders[*bootloader]
+
+	if !confirm(fmt.Sprintf("About to load %s into the bootloader at bus %d address %d.", imagePath, info.UsbBus, info.UsbAddr)) {
+		return fmt.Errorf("aborted")
+	}
+
+	err = step("Loading firmware", func() error {
+		return info.LoadFirmware(imagePath)
+	})
+
+	if err == nil && !*dryRun {
+		fmt.Println("Firmware is running from RAM. Run \"bladerf-flash firmware\" to write it to flash.")
+	}
+
+	return err
+}
+
+func run(command string, args []string) error {
+	if _, ok := extensions[command]; ok {
+		if len(args) != 1 {
+			return fmt.Errorf("%s needs exactly one image path", command)
+		}
+
+		if err := checkImage(command, args[0]); err != nil {
+			return err
+		}
+	} else if len(args) != 0 {
+		return fmt.Errorf("%s takes no arguments", command)
+	}
+
+	switch command {
+	case "firmware":
+		return withDevice("overwrite the firmware with "+args[0], func(rf *bladerf.BladeRF) error {
+			err := step("Writing firmware", func() error {
+				return rf.FlashFirmware(args[0])
+			})
+
+			if err != nil {
+				return err
+			}
+
+			return reset(rf)
+		})
+	case "fpga":
+		return withDevice("write "+args[0]+" as the autoloaded FPGA", func(rf *bladerf.BladeRF) error {
+			return step("Writing FPGA bitstream", func() error {
+				return rf.FlashFpga(args[0])
+			})
+		})
+	case "erase-fpga":
+		return withDevice("erase the autoloaded FPGA", func(rf *bladerf.BladeRF) error {
+			return step("Erasing FPGA bitstream", rf.EraseStoredFpga)
+		})
+	case "reset":
+		return withDevice("reset the device", reset)
+	case "bootloaders":
+		return listBootloaders()
+	case "recover":
+		return recoverDevice(args[0])
+	}
+
+	return fmt.Errorf("unknown command %q", command)
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), flag.Args()[1:]); err != nil {
+		fail(err)
+	}
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/erayarslan/go-bladerf"
+	exception "github.com/erayarslan/go-bladerf/error"
+	"os"
+	"text/tabwriter"
+)
+
+type rangeInfo struct {
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+	Step float64 `json:"step"`
+}
+
+type channelInfo struct {
+	Channel    string     `json:"channel"`
+	Frequency  *rangeInfo `json:"frequency,omitempty"`
+	SampleRate *rangeInfo `json:"sample_rate,omitempty"`
+	Bandwidth  *rangeInfo `json:"bandwidth,omitempty"`
+	Gain       *rangeInfo `json:"gain,omitempty"`
+}
+
+type deviceInfo struct {
+	Serial          string        `json:"serial"`
+	Board           string        `json:"board,omitempty"`
+	Backend         string        `json:"backend"`
+	UsbBus          int8          `json:"usb_bus"`
+	UsbAddr         int8          `json:"usb_addr"`
+	Instance        uint          `json:"instance"`
+	Speed           string        `json:"usb_speed,omitempty"`
+	FpgaSize        string        `json:"fpga_size,omitempty"`
+	FpgaSource      string        `json:"fpga_source,omitempty"`
+	FirmwareVersion string        `json:"firmware_version,omitempty"`
+	FpgaVersion     string        `json:"fpga_version,omitempty"`
+	VctcxoTrim      *uint16       `json:"vctcxo_trim,omitempty"`
+	ExpansionBoard  string        `json:"expansion_board,omitempty"`
+	Channels        []channelInfo `json:"channels,omitempty"`
+	Errors          []string      `json:"errors,omitempty"`
+}
+
+type report struct {
+	LibraryVersion string       `json:"library_version"`
+	Devices        []deviceInfo `json:"devices"`
+	Bootloaders    []deviceInfo `json:"bootloaders"`
+}
+
+var backends = map[bladerf.Backend]string{
+	bladerf.BackendAny:     "any",
+	bladerf.BackendLinux:   "linux",
+	bladerf.BackendLibUSB:  "libusb",
+	bladerf.BackendCypress: "cypress",
+	bladerf.BackendDummy:   "dummy",
+}
+
+var speeds = map[bladerf.DeviceSpeed]string{
+	bladerf.SpeedUnknown: "unknown",
+	bladerf.SpeedHigh:    "Hi-Speed",
+	bladerf.SpeedSuper:   "SuperSpeed",
+}
+
+var fpgaSizes = map[bladerf.FpgaSize]string{
+	bladerf.FpgaSizeUnknown: "unknown",
+	bladerf.FpgaSize40kle:   "40 kLE",
+	bladerf.FpgaSize115kle:  "115 kLE",
+	bladerf.FpgaSizeA4:      "A4 (49 kLE)",
+	bladerf.FpgaSizeA9:      "A9 (301 kLE)",
+}
+
+var fpgaSources = map[bladerf.FpgaSource]string{
+	bladerf.FpgaSourceUnknown: "unknown",
+	bladerf.FpgaSourceFlash:   "flash",
+	bladerf.FpgaSourceHost:    "host",
+}
+
+var expansionBoards = map[bladerf.ExpansionBoard]string{
+	bladerf.ExpansionBoardNone: "none",
+	bladerf.ExpansionBoard100:  "XB-100",
+	bladerf.ExpansionBoard200:  "XB-200",
+	bladerf.ExpansionBoard300:  "XB-300",
+}
+
+func newRangeInfo(_range bladerf.Range, err error) *rangeInfo {
+	if err != nil {
+		return nil
+	}
+
+	return &rangeInfo{
+		Min:  float64(_range.Min) * _range.Scale,
+		Max:  float64(_range.Max) * _range.Scale,
+		Step: float64(_range.Step) * _range.Scale,
+	}
+}
+
+func newDeviceInfo(info bladerf.DeviceInfo) deviceInfo {
+	return deviceInfo{
+		Serial:   info.Serial,
+		Backend:  backends[info.Backend],
+		UsbBus:   info.UsbBus,
+		UsbAddr:  info.UsbAddr,
+		Instance: info.Instance,
+	}
+}
+
+func (device *deviceInfo) check(operation string, err error) bool {
+	if err != nil {
+		device.Errors = append(device.Errors, fmt.Sprintf("%s: %v", operation, err))
+		return false
+	}
+
+	return true
+}
+
+func inspect(info bladerf.DeviceInfo) deviceInfo {
+	device := newDeviceInfo(info)
+	rf, err := info.Open()
+
+	if !device.check("open", err) {
+		return device
+	}
+
+	defer rf.Close()
+
+	device.Board = rf.GetBoardName()
+	device.Speed = speeds[rf.GetDeviceSpeed()]
+
+	if serial, err := rf.GetSerialStruct(); device.check("serial", err) {
+		device.Serial = serial.Serial
+	}
+
+	if size, err := rf.GetFpgaSize(); device.check("fpga size", err) {
+		device.FpgaSize = fpgaSizes[size]
+	}
+
+	if source, err := rf.GetFpgaSource(); device.check("fpga source", err) {
+		device.FpgaSource = fpgaSources[source]
+	}
+
+	if version, err := rf.GetFirmwareVersion(); device.check("firmware version", err) {
+		device.FirmwareVersion = version.Describe
+	}
+
+	if version, err := rf.GetFpgaVersion(); device.check("fpga version", err) {
+		device.FpgaVersion = version.Describe
+	}
+
+	if trim, err := rf.GetVctcxoTrim(); device.check("vctcxo trim", err) {
+		device.VctcxoTrim = &trim
+	}
+
+	if board, err := rf.GetAttachedExpansionBoard(); device.check("expansion board", err) {
+		device.ExpansionBoard = expansionBoards[board]
+	}
+
+	for _, channel := range []bladerf.Channel{
+		bladerf.ChannelRx(0), bladerf.ChannelRx(1), bladerf.ChannelTx(0), bladerf.ChannelTx(1),
+	} {
+		frequency := newRangeInfo(rf.GetFrequencyRange(channel))
+
+		if frequency == nil {
+			continue
+		}
+
+		device.Channels = append(device.Channels, channelInfo{
+			Channel:    channel.String(),
+			Frequency:  frequency,
+			SampleRate: newRangeInfo(rf.GetSampleRateRange(channel)),
+			Bandwidth:  newRangeInfo(rf.GetBandwidthRange(channel)),
+			Gain:       newRangeInfo(rf.GetGainRange(channel)),
+		})
+	}
+
+	return device
+}
+
+func printRange(writer *tabwriter.Writer, name string, _range *rangeInfo, unit string) {
+	if _range != nil {
+		fmt.Fprintf(writer, "    %s:\t%g - %g %s (step %g)\n", name, _range.Min, _range.Max, unit, _range.Step)
+	}
+}
+
+func printReport(report report) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(writer, "libbladeRF version:\t%s\n", report.LibraryVersion)
+	fmt.Fprintf(writer, "Devices found:\t%d\n", len(report.Devices))
+
+	for _, device := range report.Devices {
+		fmt.Fprintln(writer)
+		fmt.Fprintf(writer, "  Board:\t%s\n", device.Board)
+		fmt.Fprintf(writer, "  Serial #:\t%s\n", device.Serial)
+		fmt.Fprintf(writer, "  Backend:\t%s\n", device.Backend)
+		fmt.Fprintf(writer, "  USB bus:\t%d\n", device.UsbBus)
+		fmt.Fprintf(writer, "  USB address:\t%d\n", device.UsbAddr)
+		fmt.Fprintf(writer, "  USB speed:\t%s\n", device.Speed)
+		fmt.Fprintf(writer, "  Instance:\t%d\n", device.Instance)
+
+		if device.VctcxoTrim != nil {
+			fmt.Fprintf(writer, "  VCTCXO DAC calibration:\t0x%.4x\n", *device.VctcxoTrim)
+		}
+
+		fmt.Fprintf(writer, "  FPGA size:\t%s\n", device.FpgaSize)
+		fmt.Fprintf(writer, "  FPGA source:\t%s\n", device.FpgaSource)
+		fmt.Fprintf(writer, "  Firmware version:\t%s\n", device.FirmwareVersion)
+		fmt.Fprintf(writer, "  FPGA version:\t%s\n", device.FpgaVersion)
+		fmt.Fprintf(writer, "  Expansion board:\t%s\n", device.ExpansionBoard)
+
+		for _, channel := range device.Channels {
+			fmt.Fprintf(writer, "  %s:\t\n", channel.Channel)
+			printRange(writer, "Frequency", channel.Frequency, "Hz")
+			printRange(writer, "Sample rate", channel.SampleRate, "sps")
+			printRange(writer, "Bandwidth", channel.Bandwidth, "Hz")
+			printRange(writer, "Gain", channel.Gain, "dB")
+		}
+
+		for _, err := range device.Errors {
+			fmt.Fprintf(writer, "  Error:\t%s\n", err)
+		}
+	}
+
+	for _, device := range report.Bootloaders {
+		fmt.Fprintln(writer)
+		fmt.Fprintf(writer, "  Bootloader:\t%s bus %d address %d (%s)\n", device.Serial, device.UsbBus, device.UsbAddr, device.Backend)
+	}
+
+	writer.Flush()
+}
+
+// exitOnListError stops on any listing failure other than finding no
+// devices at all.
+func exitOnListError(what string, err error) {
+	if err != nil && !errors.Is(err, exception.Nodev) {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", what, err)
+		os.Exit(1)
+	}
+}
+
+func main() {
+	asJSON := flag.Bool("json", false, "print the inventory as JSON")
+	flag.Parse()
+
+	version := bladerf.GetVersion()
+	report := report{
+		LibraryVersion: fmt.Sprintf("%d.%d.%d (%s)", version.Major, version.Minor, version.Patch, version.Describe),
+		Devices:        []deviceInfo{},
+		Bootloaders:    []deviceInfo{},
+	}
+
+	devices, err := bladerf.GetDeviceList()
+	exitOnListError("device list", err)
+
+	if len(devices) > 0 {
+		defer devices[0].FreeDeviceList()
+
+		for _, info := range devices {
+			report.Devices = append(report.Devices, inspect(info))
+		}
+	}
+
+	bootloaders, err := bladerf.GetBootloaderList()
+	exitOnListError("bootloader list", err)
+
+	if len(bootloaders) > 0 {
+		defer bootloaders[0].FreeDeviceList()
+
+		for _, info := range bootloaders {
+			report.Bootloaders = append(report.Bootloaders, newDeviceInfo(info))
+		}
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+
+		if err = encoder.Encode(report); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	printReport(report)
+}
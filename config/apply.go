@@ -0,0 +1,264 @@
+package config
+
+import (
+	"fmt"
+	"github.com/erayarslan/go-bladerf"
+	"strings"
+)
+
+// ValidationError lists every setting of a profile the device cannot accept.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "config: " + strings.Join(e.Problems, "; ")
+}
+
+// ApplyError is returned when a setting failed part way through Apply.
+// RollbackErr is set if the device could not be put back as it was.
+type ApplyError struct {
+	Err         error
+	RollbackErr error
+}
+
+func (e *ApplyError) Error() string {
+	if e.RollbackErr != nil {
+		return fmt.Sprintf("config: %v (rollback failed: %v)", e.Err, e.RollbackErr)
+	}
+
+	return fmt.Sprintf("config: %v (rolled back)", e.Err)
+}
+
+func (e *ApplyError) Unwrap() error {
+	return e.Err
+}
+
+func inRange(value float64, _range bladerf.Range) bool {
+	scale := _range.Scale
+
+	if scale == 0 {
+		scale = 1
+	}
+
+	return value >= float64(_range.Min)*scale && value <= float64(_range.Max)*scale
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate checks every channel setting against the ranges, gain stages
+// and RF ports the device reports, without changing anything.
+func (profile Profile) Validate(device bladerf.Device) error {
+	if err := profile.check(); err != nil {
+		return err
+	}
+
+	validationError := &ValidationError{}
+
+	problem := func(format string, args ...interface{}) {
+		validationError.Problems = append(validationError.Problems, fmt.Sprintf(format, args...))
+	}
+
+	for _, settings := range profile.Channels {
+		channel, _ := ParseChannel(settings.Channel)
+
+		if settings.Frequency != 0 {
+			_range, err := device.GetFrequencyRange(channel)
+
+			if err != nil {
+				problem("%s: %v", channel, err)
+			} else if !inRange(float64(settings.Frequency), _range) {
+				problem("%s: frequency %d outside %d-%d", channel, settings.Frequency, _range.Min, _range.Max)
+			}
+		}
+
+		if settings.SampleRate != 0 {
+			_range, err := device.GetSampleRateRange(channel)
+
+			if err != nil {
+				problem("%s: %v", channel, err)
+			} else if !inRange(float64(settings.SampleRate), _range) {
+				problem("%s: sample rate %d outside %d-%d", channel, settings.SampleRate, _range.Min, _range.Max)
+			}
+		}
+
+		if settings.Bandwidth != 0 {
+			_range, err := device.GetBandwidthRange(channel)
+
+			if err != nil {
+				problem("%s: %v", channel, err)
+			} else if !inRange(float64(settings.Bandwidth), _range) {
+				problem("%s: bandwidth %d outside %d-%d", channel, settings.Bandwidth, _range.Min, _range.Max)
+			}
+		}
+
+		if settings.Gain != nil {
+			_range, err := device.GetGainRange(channel)
+
+			if err != nil {
+				problem("%s: %v", channel, err)
+			} else if !inRange(float64(*settings.Gain), _range) {
+				problem("%s: gain %d outside %d-%d", channel, *settings.Gain, _range.Min, _range.Max)
+			}
+		}
+
+		if len(settings.GainStages) > 0 {
+			stages, err := device.GetGainStages(channel)
+
+			if err != nil {
+				problem("%s: %v", channel, err)
+			}
+
+			for stage, gain := range settings.GainStages {
+				if !contains(stages, stage) {
+					problem("%s: unknown gain stage %q", channel, stage)
+					continue
+				}
+
+				_range, err := device.GetGainStageRange(channel, stage)
+
+				if err != nil {
+					problem("%s: %v", channel, err)
+				} else if !inRange(float64(gain), _range) {
+					problem("%s: %s gain %d outside %d-%d", channel, stage, gain, _range.Min, _range.Max)
+				}
+			}
+		}
+
+		if settings.RfPort != "" {
+			ports, err := device.GetRfPorts(channel)
+
+			if err != nil {
+				problem("%s: %v", channel, err)
+			} else if !contains(ports, settings.RfPort) {
+				problem("%s: unknown RF port %q", channel, settings.RfPort)
+			}
+		}
+	}
+
+	if len(validationError.Problems) > 0 {
+		return validationError
+	}
+
+	return nil
+}
+
+// Apply validates the profile and then configures the device. If any call
+// fails the device is restored to the Snapshot taken beforehand. Two things
+// are not rolled back: an attached expansion board, which libbladeRF cannot
+// detach, and the sync configuration of the profile's streams, which the
+// device cannot report so there is nothing to restore it to.
+func (profile Profile) Apply(device bladerf.Device) error {
+	if err := profile.Validate(device); err != nil {
+		return err
+	}
+
+	snapshot, err := device.Snapshot()
+
+	if err != nil {
+		return err
+	}
+
+	if err = profile.apply(device); err != nil {
+		return &ApplyError{Err: err, RollbackErr: device.Restore(snapshot)}
+	}
+
+	return nil
+}
+
+func (profile Profile) apply(device bladerf.Device) error {
+	if profile.ExpansionBoard != "" && profile.ExpansionBoard != "none" {
+		if err := device.AttachExpansionBoard(expansionBoards[profile.ExpansionBoard]); err != nil {
+			return err
+		}
+	}
+
+	for _, settings := range profile.Channels {
+		if err := applyChannel(device, settings); err != nil {
+			return err
+		}
+	}
+
+	for _, stream := range profile.Streams {
+		err := device.SyncConfig(
+			layouts[stream.Layout],
+			formats[stream.Format],
+			stream.NumBuffers,
+			stream.BufferSize,
+			stream.NumTransfers,
+			stream.Timeout,
+		)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, settings := range profile.Channels {
+		if settings.Enabled {
+			channel, _ := ParseChannel(settings.Channel)
+
+			if err := device.EnableModule(channel); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func applyChannel(device bladerf.Device, settings Channel) error {
+	channel, _ := ParseChannel(settings.Channel)
+
+	if settings.SampleRate != 0 {
+		if _, err := device.SetSampleRate(channel, uint(settings.SampleRate)); err != nil {
+			return err
+		}
+	}
+
+	if settings.Bandwidth != 0 {
+		if _, err := device.SetBandwidth(channel, uint(settings.Bandwidth)); err != nil {
+			return err
+		}
+	}
+
+	if settings.Frequency != 0 {
+		if err := device.SetFrequency(channel, uint64(settings.Frequency)); err != nil {
+			return err
+		}
+	}
+
+	if settings.RfPort != "" {
+		if err := device.SetRfPort(channel, settings.RfPort); err != nil {
+			return err
+		}
+	}
+
+	if settings.GainMode != "" {
+		if err := device.SetGainMode(channel, gainModes[settings.GainMode]); err != nil {
+			return err
+		}
+	}
+
+	if settings.Gain != nil {
+		if err := device.SetGain(channel, *settings.Gain); err != nil {
+			return err
+		}
+	}
+
+	for stage, gain := range settings.GainStages {
+		if err := device.SetGainStage(channel, stage, gain); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
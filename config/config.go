@@ -0,0 +1,240 @@
+// Package config loads declarative bladeRF profiles from YAML or JSON and
+// applies them to a device in one step.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/erayarslan/go-bladerf"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Hertz accepts plain numbers (915000000, 915e6) as well as strings with an
+// SI suffix ("915M", "2.4 GHz", "500k").
+type Hertz uint64
+
+type Channel struct {
+	Channel    string         `json:"channel" yaml:"channel"`
+	Frequency  Hertz          `json:"frequency,omitempty" yaml:"frequency,omitempty"`
+	SampleRate Hertz          `json:"sample_rate,omitempty" yaml:"sample_rate,omitempty"`
+	Bandwidth  Hertz          `json:"bandwidth,omitempty" yaml:"bandwidth,omitempty"`
+	GainMode   string         `json:"gain_mode,omitempty" yaml:"gain_mode,omitempty"`
+	Gain       *int           `json:"gain,omitempty" yaml:"gain,omitempty"`
+	GainStages map[string]int `json:"gain_stages,omitempty" yaml:"gain_stages,omitempty"`
+	RfPort     string         `json:"rf_port,omitempty" yaml:"rf_port,omitempty"`
+	Enabled    bool           `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+}
+
+type Stream struct {
+	Layout       string `json:"layout" yaml:"layout"`
+	Format       string `json:"format" yaml:"format"`
+	NumBuffers   uint   `json:"num_buffers" yaml:"num_buffers"`
+	BufferSize   uint   `json:"buffer_size" yaml:"buffer_size"`
+	NumTransfers uint   `json:"num_transfers" yaml:"num_transfers"`
+	Timeout      uint   `json:"timeout" yaml:"timeout"`
+}
+
+type Profile struct {
+	Device         string    `json:"device,omitempty" yaml:"device,omitempty"`
+	ExpansionBoard string    `json:"expansion_board,omitempty" yaml:"expansion_board,omitempty"`
+	Channels       []Channel `json:"channels,omitempty" yaml:"channels,omitempty"`
+	Streams        []Stream  `json:"streams,omitempty" yaml:"streams,omitempty"`
+}
+
+var gainModes = map[string]bladerf.GainMode{
+	"default":    bladerf.GainModeDefault,
+	"manual":     bladerf.GainModeManual,
+	"fast_agc":   bladerf.GainModeFastAttackAgc,
+	"slow_agc":   bladerf.GainModeSlowAttackAgc,
+	"hybrid_agc": bladerf.GainModeHybridAgc,
+}
+
+var layouts = map[string]bladerf.ChannelLayout{
+	"rx_x1": bladerf.RxX1,
+	"tx_x1": bladerf.TxX1,
+	"rx_x2": bladerf.RxX2,
+	"tx_x2": bladerf.TxX2,
+}
+
+var formats = map[string]bladerf.Format{
+	"sc16_q11":      bladerf.FormatSc16Q11,
+	"sc16_q11_meta": bladerf.FormatSc16Q11Meta,
+	"sc8_q7":        bladerf.FormatSc8Q7,
+	"sc8_q7_meta":   bladerf.FormatSc8Q7Meta,
+}
+
+var expansionBoards = map[string]bladerf.ExpansionBoard{
+	"none":  bladerf.ExpansionBoardNone,
+	"xb100": bladerf.ExpansionBoard100,
+	"xb200": bladerf.ExpansionBoard200,
+	"xb300": bladerf.ExpansionBoard300,
+}
+
+func parseHertz(text string) (Hertz, error) {
+	value := strings.TrimSuffix(strings.TrimSpace(text), "Hz")
+	value = strings.TrimSpace(value)
+	multiplier := 1.0
+
+	if len(value) > 0 {
+		switch value[len(value)-1] {
+		case 'k', 'K':
+			multiplier = 1e3
+		case 'M':
+			multiplier = 1e6
+		case 'G':
+			multiplier = 1e9
+		}
+
+		if multiplier != 1 {
+			value = strings.TrimSpace(value[:len(value)-1])
+		}
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+
+	if err != nil || number < 0 || number*multiplier > math.MaxUint64 {
+		return 0, fmt.Errorf("config: invalid frequency %q", text)
+	}
+
+	return Hertz(math.Round(number * multiplier)), nil
+}
+
+func (hertz *Hertz) UnmarshalJSON(data []byte) error {
+	text := string(data)
+
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+
+	value, err := parseHertz(text)
+	*hertz = value
+	return err
+}
+
+func (hertz *Hertz) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var text string
+
+	if err := unmarshal(&text); err != nil {
+		return err
+	}
+
+	value, err := parseHertz(text)
+	*hertz = value
+	return err
+}
+
+// ParseChannel converts names such as "RX0" or "tx1" to a bladerf.Channel.
+func ParseChannel(name string) (bladerf.Channel, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+
+	if len(name) > 2 {
+		index, err := strconv.Atoi(name[2:])
+
+		if err == nil && index >= 0 {
+			switch name[:2] {
+			case "RX":
+				return bladerf.ChannelRx(index), nil
+			case "TX":
+				return bladerf.ChannelTx(index), nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("config: invalid channel %q", name)
+}
+
+// decodeJSON rejects unknown fields, like yaml.UnmarshalStrict does for YAML.
+func decodeJSON(data []byte, profile *Profile) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(profile)
+}
+
+// Parse decodes a profile. Input starting with '{' is read as JSON and
+// anything else as YAML.
+func Parse(data []byte) (Profile, error) {
+	var profile Profile
+	var err error
+
+	if text := strings.TrimSpace(string(data)); strings.HasPrefix(text, "{") {
+		err = decodeJSON(data, &profile)
+	} else {
+		err = yaml.UnmarshalStrict(data, &profile)
+	}
+
+	if err != nil {
+		return Profile{}, err
+	}
+
+	return profile, profile.check()
+}
+
+func Load(path string) (Profile, error) {
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return Profile{}, err
+	}
+
+	var profile Profile
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = decodeJSON(data, &profile)
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(data, &profile)
+	default:
+		return Parse(data)
+	}
+
+	if err != nil {
+		return Profile{}, fmt.Errorf("config: %s: %v", path, err)
+	}
+
+	return profile, profile.check()
+}
+
+// check validates the names used in the profile, independent of any device.
+func (profile Profile) check() error {
+	if _, ok := expansionBoards[profile.ExpansionBoard]; profile.ExpansionBoard != "" && !ok {
+		return fmt.Errorf("config: unknown expansion board %q", profile.ExpansionBoard)
+	}
+
+	for _, channel := range profile.Channels {
+		if _, err := ParseChannel(channel.Channel); err != nil {
+			return err
+		}
+
+		if _, ok := gainModes[channel.GainMode]; channel.GainMode != "" && !ok {
+			return fmt.Errorf("config: %s: unknown gain mode %q", channel.Channel, channel.GainMode)
+		}
+	}
+
+	for _, stream := range profile.Streams {
+		if _, ok := layouts[stream.Layout]; !ok {
+			return fmt.Errorf("config: unknown stream layout %q", stream.Layout)
+		}
+
+		if _, ok := formats[stream.Format]; !ok {
+			return fmt.Errorf("config: unknown stream format %q", stream.Format)
+		}
+	}
+
+	return nil
+}
+
+// Open opens the device selected by the profile's devstr, or the first
+// available device when none is given.
+func (profile Profile) Open() (bladerf.BladeRF, error) {
+	if profile.Device == "" {
+		return bladerf.Open()
+	}
+
+	return bladerf.OpenWithDeviceIdentifier(profile.Device)
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"errors"
+	"github.com/erayarslan/go-bladerf"
+	"testing"
+)
+
+const profileYAML = `
+device: "*:serial=0123"
+channels:
+  - channel: RX0
+    frequency: 915M
+    sample_rate: 10e6
+    bandwidth: 5 MHz
+    gain_mode: manual
+    gain_stages:
+      full: 30
+    rf_port: B_BALANCED
+    enabled: true
+streams:
+  - layout: rx_x1
+    format: sc16_q11_meta
+    num_buffers: 16
+    buffer_size: 8192
+    num_transfers: 8
+    timeout: 3500
+`
+
+func TestParse(t *testing.T) {
+	profile, err := Parse([]byte(profileYAML))
+
+	if err == nil && profile.Device == "*:serial=0123" && profile.Channels[0].Frequency == 915000000 &&
+		profile.Channels[0].SampleRate == 10000000 && profile.Channels[0].Bandwidth == 5000000 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err)
+	}
+
+	profile, err = Parse([]byte(`{"channels": [{"channel": "tx1", "frequency": 2.4e9}]}`))
+
+	if err == nil && profile.Channels[0].Frequency == 2400000000 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err)
+	}
+
+	if _, err = Parse([]byte(`{"channels": [{"channel": "RX0", "gain_mode": "turbo"}]}`)); err != nil {
+		t.Log("PASSED")
+	} else {
+		t.Error("FAILED cause unknown gain mode was accepted")
+	}
+
+	if _, err = Parse([]byte(`{"channels": [{"channel": "RX0", "frequncy": 915e6}]}`)); err != nil {
+		t.Log("PASSED")
+	} else {
+		t.Error("FAILED cause unknown JSON field was accepted")
+	}
+}
+
+func TestApply(t *testing.T) {
+	rf := bladerf.NewSimulator()
+	defer rf.Close()
+
+	profile, _ := Parse([]byte(profileYAML))
+	err := profile.Apply(rf)
+
+	rx := bladerf.ChannelRx(0)
+	frequency, _ := rf.GetFrequency(rx)
+	gain, _ := rf.GetGainStage(rx, "full")
+	port, _ := rf.GetRfPort(rx)
+
+	if err == nil && frequency == 915000000 && gain == 30 && port == "B_BALANCED" && rf.IsModuleEnabled(rx) {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	rf := bladerf.NewSimulator()
+	defer rf.Close()
+
+	profile, _ := Parse([]byte(`{"channels": [{"channel": "RX0", "frequency": "7G", "gain_stages": {"lna": 3}}]}`))
+	err := profile.Apply(rf)
+
+	if validationError, ok := err.(*ValidationError); ok && len(validationError.Problems) == 2 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err)
+	}
+
+	profile, _ = Parse([]byte(`{"channels": [{"channel": "RX0", "frequency": "915M", "sample_rate": "1G"}]}`))
+	err = profile.Validate(noFrequencyRange{rf})
+
+	if validationError, ok := err.(*ValidationError); ok && len(validationError.Problems) == 2 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err)
+	}
+}
+
+type noFrequencyRange struct {
+	*bladerf.Simulator
+}
+
+func (noFrequencyRange) GetFrequencyRange(channel bladerf.Channel) (bladerf.Range, error) {
+	return bladerf.Range{}, errors.New("no frequency range")
+}
+
+func TestApplyRollback(t *testing.T) {
+	rf := bladerf.NewSimulator()
+	defer rf.Close()
+
+	rx := bladerf.ChannelRx(0)
+	_ = rf.SetFrequency(rx, 100000000)
+
+	profile, _ := Parse([]byte(`{
+		"channels": [{"channel": "RX0", "frequency": "915M"}],
+		"streams": [{"layout": "rx_x1", "format": "sc16_q11", "num_buffers": 16, "buffer_size": 1000, "num_transfers": 8}]
+	}`))
+	err := profile.Apply(rf)
+	frequency, _ := rf.GetFrequency(rx)
+
+	if applyError, ok := err.(*ApplyError); ok && applyError.RollbackErr == nil && frequency == 100000000 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err)
+	}
+}
@@ -0,0 +1,427 @@
+package bladerf
+
+import (
+	exception "github.com/erayarslan/go-bladerf/error"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	calibrationLead          = 20 * time.Millisecond
+	calibrationSamples       = 4096
+	calibrationSettle        = 1024
+	calibrationTimeout       = 1000
+	calibrationToneAmplitude = 1024
+)
+
+var dcCorrections = [2]Correction{CorrectionDcoffI, CorrectionDcoffQ}
+var iqCorrections = [2]Correction{CorrectionGain, CorrectionPhase}
+
+// CorrectionPoint is the set of corrections measured for a channel at one
+// frequency.
+type CorrectionPoint struct {
+	Frequency   uint64
+	Corrections map[Correction]int16
+}
+
+// CorrectionTable holds calibrated corrections per channel, sorted by
+// frequency. Once installed with SetCorrectionTable it is applied by every
+// SetFrequency call.
+type CorrectionTable struct {
+	Channels map[Channel][]CorrectionPoint
+}
+
+func NewCorrectionTable() *CorrectionTable {
+	return &CorrectionTable{Channels: make(map[Channel][]CorrectionPoint)}
+}
+
+// Add stores the corrections of channel at frequency, replacing any point
+// already measured there.
+func (table *CorrectionTable) Add(channel Channel, frequency uint64, corrections map[Correction]int16) {
+	points := table.Channels[channel]
+	index := sort.Search(len(points), func(i int) bool {
+		return points[i].Frequency >= frequency
+	})
+
+	point := CorrectionPoint{Frequency: frequency, Corrections: corrections}
+
+	if index < len(points) && points[index].Frequency == frequency {
+		points[index] = point
+	} else {
+		points = append(points, CorrectionPoint{})
+		copy(points[index+1:], points[index:])
+		points[index] = point
+	}
+
+	table.Channels[channel] = points
+}
+
+// Lookup returns the corrections of channel at frequency, interpolated
+// linearly between the two nearest points and held at the first or last
+// point outside the calibrated span.
+func (table *CorrectionTable) Lookup(channel Channel, frequency uint64) (map[Correction]int16, bool) {
+	points := table.Channels[channel]
+
+	if len(points) == 0 {
+		return nil, false
+	}
+
+	index := sort.Search(len(points), func(i int) bool {
+		return points[i].Frequency >= frequency
+	})
+
+	if index == 0 {
+		return points[0].Corrections, true
+	} else if index == len(points) {
+		return points[len(points)-1].Corrections, true
+	}
+
+	lower, upper := points[index-1], points[index]
+	ratio := float64(frequency-lower.Frequency) / float64(upper.Frequency-lower.Frequency)
+	corrections := make(map[Correction]int16, len(lower.Corrections))
+
+	for correction, value := range lower.Corrections {
+		next, ok := upper.Corrections[correction]
+
+		if !ok {
+			next = value
+		}
+
+		corrections[correction] = int16(math.Round(float64(value) + ratio*float64(next-value)))
+	}
+
+	return corrections, true
+}
+
+// Apply sets the corrections of channel for frequency. Channels without any
+// calibrated point are left alone.
+func (table *CorrectionTable) Apply(device Device, channel Channel, frequency uint64) error {
+	corrections, ok := table.Lookup(channel, frequency)
+
+	if !ok {
+		return nil
+	}
+
+	for _, correction := range snapshotCorrections {
+		if value, ok := corrections[correction]; ok {
+			if err := device.SetCorrection(channel, correction, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// captureLoopback schedules tx, if any, and the RX window at the same
+// timestamp, lead samples ahead of the current TX timestamp. The window is
+// received into buf, which must hold calibrationSettle+calibrationSamples
+// samples, and the part after the settling period is returned.
+func captureLoopback(device Device, lead Timestamp, tx []int16, buf []int16) ([]int16, error) {
+	now, err := device.GetTimestamp(Tx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	at := now + lead
+
+	if tx != nil {
+		flags := MetaFlagTxBurstStart | MetaFlagTxBurstEnd
+
+		if _, err = device.SyncTX(tx, NewMetadata(at, flags), calibrationTimeout); err != nil {
+			return nil, err
+		}
+	}
+
+	count, _, err := device.SyncRXInto(buf, NewMetadata(at, 0), calibrationTimeout)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if count <= calibrationSettle {
+		return nil, codeError(exception.Io, "calibrate_corrections", "samples", count)
+	}
+
+	return buf[2*calibrationSettle : 2*count], nil
+}
+
+// dcPower is the power of the mean of the samples, which is what is left
+// of a DC offset or LO leakage once any tone has averaged out.
+func dcPower(samples []int16) float64 {
+	var i, q float64
+
+	for n := 0; n+1 < len(samples); n += 2 {
+		i += float64(samples[n])
+		q += float64(samples[n+1])
+	}
+
+	count := float64(len(samples) / 2)
+	return (i*i + q*q) / (count * count)
+}
+
+// imageRatio compares the power at -fs/8 to that of the +fs/8 test tone.
+func imageRatio(samples []int16) float64 {
+	var toneI, toneQ, imageI, imageQ float64
+
+	for n := 0; n+1 < len(samples); n += 2 {
+		angle := 2 * math.Pi * float64(n/2) / 8
+		cos, sin := math.Cos(angle), math.Sin(angle)
+		i, q := float64(samples[n]), float64(samples[n+1])
+
+		toneI += i*cos + q*sin
+		toneQ += q*cos - i*sin
+		imageI += i*cos - q*sin
+		imageQ += q*cos + i*sin
+	}
+
+	tone := toneI*toneI + toneQ*toneQ
+
+	if tone == 0 {
+		return math.Inf(1)
+	}
+
+	return (imageI*imageI + imageQ*imageQ) / tone
+}
+
+func calibrationTone() []int16 {
+	tone := make([]int16, 2*(calibrationSettle+calibrationSamples))
+
+	for n := 0; n < len(tone)/2; n++ {
+		angle := 2 * math.Pi * float64(n) / 8
+		tone[2*n] = int16(math.Round(calibrationToneAmplitude * math.Cos(angle)))
+		tone[2*n+1] = int16(math.Round(calibrationToneAmplitude * math.Sin(angle)))
+	}
+
+	return tone
+}
+
+// minimizeCorrections runs a coordinate descent over a pair of corrections,
+// starting from their current values and halving the step whenever no
+// single move lowers the cost. The best values are left set on the device.
+func minimizeCorrections(device Device, channel Channel, corrections [2]Correction, limit int16, cost func() (float64, error)) (map[Correction]int16, error) {
+	var values [2]int16
+	var err error
+
+	for k, correction := range corrections {
+		if values[k], err = device.GetCorrection(channel, correction); err != nil {
+			return nil, err
+		}
+	}
+
+	best, err := cost()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for step := limit / 8; step > 0; step /= 2 {
+		for improved := true; improved; {
+			improved = false
+
+			for k, correction := range corrections {
+				for _, candidate := range []int16{values[k] + step, values[k] - step} {
+					if candidate > limit || candidate < -limit {
+						continue
+					}
+
+					if err = device.SetCorrection(channel, correction, candidate); err != nil {
+						return nil, err
+					}
+
+					value, err := cost()
+
+					if err != nil {
+						return nil, err
+					}
+
+					if value < best {
+						best, values[k], improved = value, candidate, true
+						break
+					}
+				}
+
+				if err = device.SetCorrection(channel, correction, values[k]); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return map[Correction]int16{corrections[0]: values[0], corrections[1]: values[1]}, nil
+}
+
+func calibrationLoopback(device Device) (Loopback, error) {
+	for _, loopback := range []Loopback{LoopbackRficBist, LoopbackBbTxlpfRxlpf} {
+		if device.IsLoopbackModeSupported(loopback) {
+			return loopback, nil
+		}
+	}
+
+	return LoopbackDisabled, codeError(exception.Unsupported, "calibrate_corrections", "lb", "none")
+}
+
+// calibrateFrequency nulls the RX DC offset with loopback off, then closes
+// the loopback and nulls the TX LO leakage while sending zeros, and finally
+// minimizes the image of a +fs/8 tone with the TX gain and phase corrections.
+func calibrateFrequency(device Device, rx Channel, tx Channel, loopback Loopback, lead Timestamp, buf []int16, frequency uint64) (map[Correction]int16, map[Correction]int16, error) {
+	if err := device.SetFrequency(rx, frequency); err != nil {
+		return nil, nil, err
+	}
+
+	if err := device.SetFrequency(tx, frequency); err != nil {
+		return nil, nil, err
+	}
+
+	if err := device.SetLoopback(LoopbackDisabled); err != nil {
+		return nil, nil, err
+	}
+
+	rxValues, err := minimizeCorrections(device, rx, dcCorrections, 2047, func() (float64, error) {
+		samples, err := captureLoopback(device, lead, nil, buf)
+		return dcPower(samples), err
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = device.SetLoopback(loopback); err != nil {
+		return nil, nil, err
+	}
+
+	silence := make([]int16, 2*(calibrationSettle+calibrationSamples))
+
+	txValues, err := minimizeCorrections(device, tx, dcCorrections, 2047, func() (float64, error) {
+		samples, err := captureLoopback(device, lead, silence, buf)
+		return dcPower(samples), err
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tone := calibrationTone()
+
+	iqValues, err := minimizeCorrections(device, tx, iqCorrections, 4096, func() (float64, error) {
+		samples, err := captureLoopback(device, lead, tone, buf)
+		return imageRatio(samples), err
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for correction, value := range iqValues {
+		txValues[correction] = value
+	}
+
+	return rxValues, txValues, nil
+}
+
+// calibrateCorrections measures every frequency, then puts the loopback,
+// frequencies and module states back as they were. The results are merged
+// into the device's correction table, which is installed if there was none.
+// Both sync interfaces must use FormatSc16Q11Meta so captures can be timed.
+func calibrateCorrections(device Device, rxFormat Format, txFormat Format, rx Channel, tx Channel, frequencies []uint64) (table *CorrectionTable, err error) {
+	if rxFormat != FormatSc16Q11Meta || txFormat != FormatSc16Q11Meta {
+		return nil, codeError(exception.Inval, "calibrate_corrections", "rx", rxFormat, "tx", txFormat)
+	}
+
+	loopback, err := calibrationLoopback(device)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rate, err := device.GetRationalSampleRate(tx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	lead := durationToTicks(calibrationLead, rate)
+	buf := make([]int16, 2*(calibrationSettle+calibrationSamples))
+
+	previousLoopback, err := device.GetLoopback()
+
+	if err != nil {
+		return nil, err
+	}
+
+	rxFrequency, err := device.GetFrequency(rx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	txFrequency, err := device.GetFrequency(tx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	table = device.GetCorrectionTable()
+
+	if table == nil {
+		table = NewCorrectionTable()
+	}
+
+	device.SetCorrectionTable(nil)
+
+	defer func() {
+		device.SetCorrectionTable(table)
+
+		restore := []error{
+			device.SetLoopback(previousLoopback),
+			device.SetFrequency(rx, rxFrequency),
+			device.SetFrequency(tx, txFrequency),
+		}
+
+		for _, restoreErr := range restore {
+			if err == nil && restoreErr != nil {
+				err = restoreErr
+			}
+		}
+	}()
+
+	for _, channel := range []Channel{rx, tx} {
+		if !device.IsModuleEnabled(channel) {
+			if err = device.EnableModule(channel); err != nil {
+				return table, err
+			}
+
+			defer device.DisableModule(channel)
+		}
+	}
+
+	for _, frequency := range frequencies {
+		rxValues, txValues, err := calibrateFrequency(device, rx, tx, loopback, lead, buf, frequency)
+
+		if err != nil {
+			return table, err
+		}
+
+		table.Add(rx, frequency, rxValues)
+		table.Add(tx, frequency, txValues)
+	}
+
+	return table, nil
+}
+
+// CalibrateCorrections sweeps the DC offset, gain and phase corrections at
+// each frequency using the RFIC BIST loopback, or the baseband loopback on a
+// bladeRF 1. The RX and TX sync interfaces must already be configured for
+// FormatSc16Q11Meta, since each tone and its capture are scheduled at the
+// same timestamp. The resulting table is installed and returned.
+func (bladeRF *BladeRF) CalibrateCorrections(rx Channel, tx Channel, frequencies []uint64) (*CorrectionTable, error) {
+	return calibrateCorrections(bladeRF, bladeRF.syncFormats[Rx], bladeRF.syncFormats[Tx], rx, tx, frequencies)
+}
+
+func (simulator *Simulator) CalibrateCorrections(rx Channel, tx Channel, frequencies []uint64) (*CorrectionTable, error) {
+	rxFormat, _ := simulator.syncSettings(Rx)
+	txFormat, _ := simulator.syncSettings(Tx)
+	return calibrateCorrections(simulator, rxFormat, txFormat, rx, tx, frequencies)
+}
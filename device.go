@@ -0,0 +1,202 @@
+package bladerf
+
+import (
+	"context"
+	"io"
+)
+
+// Device is the set of operations shared by a libbladeRF backed BladeRF and
+// the in-process Simulator, so code can be written once and run against either.
+type Device interface {
+	LoadFpga(imagePath string) error
+	FlashFirmware(imagePath string) error
+	FlashFpga(imagePath string) error
+	EraseStoredFpga() error
+	DeviceReset() error
+	JumpToBootloader() error
+	GetFpgaSize() (FpgaSize, error)
+	GetQuickTune(channel Channel) (QuickTune, error)
+	CancelScheduledReTunes(channel Channel) error
+	GetFpgaSource() (FpgaSource, error)
+	GetFpgaBytes() (uint32, error)
+	GetFpgaFlashSize() (uint32, bool, error)
+	GetFirmwareVersion() (Version, error)
+	IsFpgaConfigured() (bool, error)
+	GetDeviceSpeed() DeviceSpeed
+	GetFpgaVersion() (Version, error)
+	GetDeviceInfo() (DeviceInfo, error)
+	Close()
+	SetLoopback(loopback Loopback) error
+	IsLoopbackModeSupported(loopback Loopback) bool
+	GetLoopback() (Loopback, error)
+	ScheduleReTune(channel Channel, timestamp Timestamp, frequency uint64, quickTune QuickTune) error
+	SelectBand(channel Channel, frequency uint64) error
+	SetFrequency(channel Channel, frequency uint64) error
+	GetFrequency(channel Channel) (uint64, error)
+	SetSampleRate(channel Channel, sampleRate uint) (uint, error)
+	SetRxMux(mux RxMux) error
+	GetRxMux() (RxMux, error)
+	SetRationalSampleRate(channel Channel, rationalRate RationalRate) (RationalRate, error)
+	GetSampleRate(channel Channel) (uint, error)
+	GetRationalSampleRate(channel Channel) (RationalRate, error)
+	GetSampleRateRange(channel Channel) (Range, error)
+	GetFrequencyRange(channel Channel) (Range, error)
+	SetBandwidth(channel Channel, bandwidth uint) (uint, error)
+	GetBandwidth(channel Channel) (uint, error)
+	GetBandwidthRange(channel Channel) (Range, error)
+	SetGain(channel Channel, gain int) error
+	GetGain(channel Channel) (int, error)
+	GetGainStage(channel Channel, stage string) (int, error)
+	GetGainMode(channel Channel) (GainMode, error)
+	SetGainStage(channel Channel, stage string, gain int) error
+	GetGainStageRange(channel Channel, stage string) (Range, error)
+	GetGainRange(channel Channel) (Range, error)
+	GetNumberOfGainStages(channel Channel) (int, error)
+	SetCorrection(channel Channel, correction Correction, correctionValue int16) error
+	GetCorrection(channel Channel, correction Correction) (int16, error)
+	SetCorrectionTable(table *CorrectionTable)
+	GetCorrectionTable() *CorrectionTable
+	CalibrateCorrections(rx Channel, tx Channel, frequencies []uint64) (*CorrectionTable, error)
+	GetBoardName() string
+	GetSerial() (string, error)
+	GetSerialStruct() (Serial, error)
+	GetGainStages(channel Channel) ([]string, error)
+	GetGainModes(channel Channel) ([]GainModes, error)
+	GetLoopbackModes() ([]LoopbackModes, error)
+	SetGainMode(channel Channel, mode GainMode) error
+	EnableModule(channel Channel) error
+	DisableModule(channel Channel) error
+	IsModuleEnabled(channel Channel) bool
+	TriggerInit(channel Channel, signal TriggerSignal) (Trigger, error)
+	TriggerArm(trigger Trigger, arm bool, resV1 uint64, resV2 uint64) error
+	TriggerFire(trigger Trigger) error
+	TriggerState(trigger Trigger) (bool, bool, bool, uint64, uint64, error)
+	SyncTX(input []int16, metadata Metadata, timeout uint) (Metadata, error)
+	SyncRX(bufferSize uintptr, metadata Metadata, timeout uint) ([]int16, Metadata, error)
+	SyncTXFrom(buf []int16, metadata Metadata, timeout uint) (Metadata, error)
+	SyncRXInto(buf []int16, metadata Metadata, timeout uint) (int, Metadata, error)
+	SyncTX8(input []int8, metadata Metadata, timeout uint) (Metadata, error)
+	SyncRX8(bufferSize uintptr, metadata Metadata, timeout uint) ([]int8, Metadata, error)
+	SyncTX8From(buf []int8, metadata Metadata, timeout uint) (Metadata, error)
+	SyncRX8Into(buf []int8, metadata Metadata, timeout uint) (int, Metadata, error)
+	TransmitBurst(ctx context.Context, samples []int16, at Timestamp) error
+	TransmitBursts(ctx context.Context, bursts []Burst) (int, error)
+	InitStream(
+		format Format,
+		numBuffers int,
+		samplesPerBuffer int,
+		numTransfers int,
+		callback func(data []int16) GoStream,
+	) (Stream, error)
+	InitStream8(
+		format Format,
+		numBuffers int,
+		samplesPerBuffer int,
+		numTransfers int,
+		callback func(data []int8) GoStream,
+	) (Stream, error)
+	StartRX(ctx context.Context, config StreamConfig) (<-chan SampleBlock, error)
+	GetStreamTimeout(direction Direction) (uint, error)
+	SetStreamTimeout(direction Direction, timeout uint) error
+	SyncConfig(
+		layout ChannelLayout,
+		format Format,
+		numBuffers uint,
+		bufferSize uint,
+		numTransfers uint,
+		timeout uint,
+	) error
+	AttachExpansionBoard(expansionBoard ExpansionBoard) error
+	GetAttachedExpansionBoard() (ExpansionBoard, error)
+	XB200SetFilterbank(channel Channel, filter XB200Filter) error
+	XB200GetFilterbank(channel Channel) (XB200Filter, error)
+	XB200SetPath(channel Channel, path XB200Path) error
+	XB200GetPath(channel Channel) (XB200Path, error)
+	XB300SetTrx(trx XB300Trx) error
+	XB300GetTrx() (XB300Trx, error)
+	XB300SetAmplifierEnable(amplifier XB300Amplifier, enable bool) error
+	XB300GetAmplifierEnable(amplifier XB300Amplifier) (bool, error)
+	XB300GetOutputPower() (float32, error)
+	XB300SwitchTo(direction Direction) error
+	GetRficRssi(channel Channel) (int32, int32, error)
+	GetRficTemperature() (float32, error)
+	GetRficCtrlOut() (uint8, error)
+	GetRficRxFir() (RficRxFir, error)
+	SetRficRxFir(fir RficRxFir) error
+	GetRficTxFir() (RficTxFir, error)
+	SetRficTxFir(fir RficTxFir) error
+	GetRficRegister(address uint16) (uint8, error)
+	SetRficRegister(address uint16, val uint8) error
+	SetTxMute(channel Channel, mute bool) error
+	GetTxMute(channel Channel) (bool, error)
+	SetBiasTee(channel Channel, enable bool) error
+	GetBiasTee(channel Channel) (bool, error)
+	SetClockSelect(selection ClockSelect) error
+	GetClockSelect() (ClockSelect, error)
+	SetClockOutput(enable bool) error
+	GetClockOutput() (bool, error)
+	SetPllEnable(enable bool) error
+	GetPllEnable() (bool, error)
+	SetPllRefClk(frequency uint64) error
+	GetPllRefClk() (uint64, error)
+	GetPllRefClkRange() (Range, error)
+	GetPllLockState() (bool, error)
+	WaitPLLLock(ctx context.Context) error
+	GetPmicRegister(register PmicRegister) (float64, error)
+	GetPowerSource() (PowerSource, error)
+	ReadPower() (PowerReading, error)
+	LmsRead(address uint8) (uint8, error)
+	LmsWrite(address uint8, val uint8) error
+	LmsSetDcCals(dcCals LmsDcCals) error
+	LmsGetDcCals() (LmsDcCals, error)
+	ReadLmsRegisters() (LmsRegisters, error)
+	Si5338Read(address uint8) (uint8, error)
+	Si5338Write(address uint8, val uint8) error
+	Si5338SetTxFreq(frequency uint) error
+	Si5338SetRxFreq(frequency uint) error
+	Si5338SetSmbFreq(frequency uint) error
+	SetVctcxoTamerMode(mode VctcxoTamerMode) error
+	GetVctcxoTamerMode() (VctcxoTamerMode, error)
+	GetVctcxoTrim() (uint16, error)
+	TrimDacRead() (uint16, error)
+	TrimDacWrite(val uint16) error
+	SetTuningMode(mode TuningMode) error
+	GetTuningMode() (TuningMode, error)
+	GetTimestamp(direction Direction) (Timestamp, error)
+	ReadTrigger(channel Channel, signal TriggerSignal) (uint8, error)
+	WriteTrigger(channel Channel, signal TriggerSignal, val uint8) error
+	ConfigGpioRead() (uint32, error)
+	ConfigGpioWrite(val uint32) error
+	ExpansionGpioRead() (uint32, error)
+	ExpansionGpioWrite(val uint32) error
+	ExpansionGpioMaskedWrite(mask uint32, val uint32) error
+	ExpansionGpioDirRead() (uint32, error)
+	ExpansionGpioDirWrite(outputs uint32) error
+	ExpansionGpioDirMaskedWrite(mask uint32, outputs uint32) error
+	EraseFlash(eraseBlock uint32, count uint32) error
+	EraseFlashBytes(address uint32, length uint32) error
+	LockOtp() error
+	ReadFlashBytes(address uint32, bytes uint32) ([]uint8, error)
+	WriteFlashBytes(input []uint8, address uint32, bytes uint32) error
+	ReadOtp() ([]uint8, error)
+	WriteOtp(input []uint8) error
+	ReadFlash(page uint32, count uint32) ([]uint8, error)
+	WriteFlash(input []uint8, page uint32, count uint32) error
+	SetRfPort(channel Channel, port string) error
+	GetRfPort(channel Channel) (string, error)
+	GetNumberOfRfPorts(channel Channel) (int, error)
+	GetRfPorts(channel Channel) ([]string, error)
+	Snapshot() (Snapshot, error)
+	Restore(snapshot Snapshot) error
+	BackupFlash(path string) error
+	RestoreFlash(path string) error
+	ReadCalibration() (Calibration, error)
+	WriteCalibration(calibration Calibration, dryRun io.Writer) error
+	StoreVctcxoTrim(trim uint16, dryRun io.Writer) error
+	ReadOtpFields() (Otp, error)
+	WriteOtpFields(otp Otp, dryRun io.Writer) error
+	LockOtpFields(dryRun io.Writer) error
+}
+
+var _ Device = (*BladeRF)(nil)
+var _ Device = (*Simulator)(nil)
@@ -24,9 +24,16 @@ type TriggerSignal int
 type ExpansionBoard int
 type VctcxoTamerMode int
 type TuningMode int
+type XB200Filter int
+type XB200Path int
+type XB300Trx int
+type XB300Amplifier int
+type RficRxFir int
+type RficTxFir int
 type GoStream int
 
-const FlashPageSize = 256 // BLADERF_FLASH_PAGE_SIZE - Size of the SPI flash, in pages
+const FlashPageSize = 256         // BLADERF_FLASH_PAGE_SIZE - Size of the SPI flash, in pages
+const FlashEraseBlockSize = 65536 // Size of a SPI flash erase block, in bytes
 
 const (
 	GoStreamNext     GoStream = 0
@@ -74,6 +81,8 @@ const (
 const (
 	FormatSc16Q11     Format = C.BLADERF_FORMAT_SC16_Q11
 	FormatSc16Q11Meta Format = C.BLADERF_FORMAT_SC16_Q11_META
+	FormatSc8Q7       Format = C.BLADERF_FORMAT_SC8_Q7
+	FormatSc8Q7Meta   Format = C.BLADERF_FORMAT_SC8_Q7_META
 )
 
 const (
@@ -181,3 +190,47 @@ const (
 	TuningModeHost    TuningMode = C.BLADERF_TUNING_MODE_HOST
 	TuningModeFpga    TuningMode = C.BLADERF_TUNING_MODE_FPGA
 )
+
+const (
+	XB200Filter50M     XB200Filter = C.BLADERF_XB200_50M
+	XB200Filter144M    XB200Filter = C.BLADERF_XB200_144M
+	XB200Filter222M    XB200Filter = C.BLADERF_XB200_222M
+	XB200FilterCustom  XB200Filter = C.BLADERF_XB200_CUSTOM
+	XB200FilterAuto1dB XB200Filter = C.BLADERF_XB200_AUTO_1DB
+	XB200FilterAuto3dB XB200Filter = C.BLADERF_XB200_AUTO_3DB
+)
+
+const (
+	XB200PathBypass XB200Path = C.BLADERF_XB200_BYPASS
+	XB200PathMix    XB200Path = C.BLADERF_XB200_MIX
+)
+
+const (
+	XB300TrxInvalid XB300Trx = C.BLADERF_XB300_TRX_INVAL
+	XB300TrxTx      XB300Trx = C.BLADERF_XB300_TRX_TX
+	XB300TrxRx      XB300Trx = C.BLADERF_XB300_TRX_RX
+	XB300TrxUnset   XB300Trx = C.BLADERF_XB300_TRX_UNSET
+)
+
+const (
+	XB300AmplifierInvalid XB300Amplifier = C.BLADERF_XB300_AMP_INVAL
+	XB300AmplifierPa      XB300Amplifier = C.BLADERF_XB300_AMP_PA
+	XB300AmplifierLna     XB300Amplifier = C.BLADERF_XB300_AMP_LNA
+	XB300AmplifierPaAux   XB300Amplifier = C.BLADERF_XB300_AMP_PA_AUX
+)
+
+const (
+	RficRxFirBypass RficRxFir = C.BLADERF_RFIC_RXFIR_BYPASS
+	RficRxFirCustom RficRxFir = C.BLADERF_RFIC_RXFIR_CUSTOM
+	RficRxFirDec1   RficRxFir = C.BLADERF_RFIC_RXFIR_DEC1
+	RficRxFirDec2   RficRxFir = C.BLADERF_RFIC_RXFIR_DEC2
+	RficRxFirDec4   RficRxFir = C.BLADERF_RFIC_RXFIR_DEC4
+)
+
+const (
+	RficTxFirBypass RficTxFir = C.BLADERF_RFIC_TXFIR_BYPASS
+	RficTxFirCustom RficTxFir = C.BLADERF_RFIC_TXFIR_CUSTOM
+	RficTxFirInt1   RficTxFir = C.BLADERF_RFIC_TXFIR_INT1
+	RficTxFirInt2   RficTxFir = C.BLADERF_RFIC_TXFIR_INT2
+	RficTxFirInt4   RficTxFir = C.BLADERF_RFIC_TXFIR_INT4
+)
@@ -72,18 +72,47 @@ func codeToString(code Code) string {
 	return "InvalidError"
 }
 
-type errorString struct {
-	s string
+func (code Code) Error() string {
+	return codeToString(code)
 }
 
-func (e *errorString) Error() string {
-	return e.s
+type Error struct {
+	Code      Code
+	Operation string
+	Arguments string
+}
+
+func (e *Error) Error() string {
+	if e.Operation == "" {
+		return codeToString(e.Code)
+	}
+
+	if e.Arguments == "" {
+		return e.Operation + ": " + codeToString(e.Code)
+	}
+
+	return e.Operation + " " + e.Arguments + ": " + codeToString(e.Code)
+}
+
+func (e *Error) Is(target error) bool {
+	switch target := target.(type) {
+	case Code:
+		return e.Code == target
+	case *Error:
+		return e.Code == target.Code
+	}
+
+	return false
 }
 
 func New(code int) error {
+	return NewWithOperation(code, "", "")
+}
+
+func NewWithOperation(code int, operation string, arguments string) error {
 	if code == 0 {
 		return nil
 	}
 
-	return &errorString{s: codeToString(Code(code))}
+	return &Error{Code: Code(code), Operation: operation, Arguments: arguments}
 }
@@ -0,0 +1,473 @@
+package bladerf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	exception "github.com/erayarslan/go-bladerf/error"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	CalibrationAddress = 0x30000 // Flash address of the calibration region
+	CalibrationSize    = 256
+	OtpSize            = 256
+)
+
+// fieldKeys are the keys libbladeRF writes, longest first. Keys and values
+// are stored back to back, so a key is only recognised by its prefix.
+var fieldKeys = []string{"DAC", "S", "B"}
+
+// Field is a single key/value entry of the OTP or calibration region. On
+// the device each entry is stored as a length byte, the key immediately
+// followed by the value, and a little endian CRC16 of all of the above.
+type Field struct {
+	Key   string
+	Value string
+}
+
+type FieldTable []Field
+
+// fieldCrc is the ZMODEM CRC16 (polynomial 0x1021, initial value 0) used by
+// libbladeRF's zcrc.
+func fieldCrc(data []byte) uint16 {
+	var crc uint16
+
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return crc
+}
+
+func splitField(entry string) Field {
+	for _, key := range fieldKeys {
+		if strings.HasPrefix(entry, key) {
+			return Field{Key: key, Value: entry[len(key):]}
+		}
+	}
+
+	end := strings.IndexFunc(entry, func(r rune) bool { return r < 'A' || r > 'Z' })
+
+	if end < 0 {
+		return Field{Key: entry}
+	}
+
+	return Field{Key: entry[:end], Value: entry[end:]}
+}
+
+// ParseFieldTable reads entries until the first erased (0xff) length byte.
+// A CRC mismatch is reported as exception.Checksum, since anything past a
+// corrupted entry cannot be located reliably.
+func ParseFieldTable(buf []byte) (FieldTable, error) {
+	var table FieldTable
+
+	for offset := 0; offset < len(buf) && buf[offset] != 0xff; {
+		length := int(buf[offset])
+
+		if offset+length+3 > len(buf) {
+			return table, codeError(exception.Checksum, "parse_fields", "offset", offset, "len", length)
+		}
+
+		expected := binary.LittleEndian.Uint16(buf[offset+length+1:])
+
+		if fieldCrc(buf[offset:offset+length+1]) != expected {
+			return table, codeError(exception.Checksum, "parse_fields", "offset", offset, "len", length)
+		}
+
+		table = append(table, splitField(string(buf[offset+1:offset+length+1])))
+		offset += length + 3
+	}
+
+	return table, nil
+}
+
+func (table FieldTable) Get(key string) (string, bool) {
+	for _, field := range table {
+		if field.Key == key {
+			return field.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// Set replaces the value of key, or appends it when the table lacks it.
+func (table FieldTable) Set(key string, value string) FieldTable {
+	for i, field := range table {
+		if field.Key == key {
+			table[i].Value = value
+			return table
+		}
+	}
+
+	return append(table, Field{Key: key, Value: value})
+}
+
+// Encode lays the table out in a size byte region, padding the remainder
+// with 0xff like erased flash.
+func (table FieldTable) Encode(size int) ([]byte, error) {
+	buf := bytes.Repeat([]byte{0xff}, size)
+	offset := 0
+
+	for _, field := range table {
+		length := len(field.Key) + len(field.Value)
+
+		if length >= 0xff {
+			return nil, codeError(exception.Inval, "encode_field", "key", field.Key, "len", length)
+		}
+
+		if offset+length+3 > size {
+			return nil, codeError(exception.Mem, "encode_field", "key", field.Key, "len", length)
+		}
+
+		buf[offset] = uint8(length)
+		copy(buf[offset+1:], field.Key)
+		copy(buf[offset+1+len(field.Key):], field.Value)
+		binary.LittleEndian.PutUint16(buf[offset+length+1:], fieldCrc(buf[offset:offset+length+1]))
+		offset += length + 3
+	}
+
+	return buf, nil
+}
+
+func (table FieldTable) String() string {
+	fields := make([]string, len(table))
+
+	for i, field := range table {
+		fields[i] = field.Key + "=" + field.Value
+	}
+
+	return strings.Join(fields, " ")
+}
+
+// Otp is the one-time programmable region holding the serial number and
+// board revision. Extra keeps any other fields in their original order.
+type Otp struct {
+	Serial        string
+	BoardRevision string
+	Extra         FieldTable
+}
+
+func ParseOtp(buf []byte) (Otp, error) {
+	table, err := ParseFieldTable(buf)
+
+	if err != nil {
+		return Otp{}, err
+	}
+
+	var otp Otp
+
+	for _, field := range table {
+		switch field.Key {
+		case "S":
+			otp.Serial = field.Value
+		case "B":
+			otp.BoardRevision = field.Value
+		default:
+			otp.Extra = append(otp.Extra, field)
+		}
+	}
+
+	return otp, nil
+}
+
+func (otp Otp) Table() FieldTable {
+	var table FieldTable
+
+	if otp.Serial != "" {
+		table = append(table, Field{Key: "S", Value: otp.Serial})
+	}
+
+	if otp.BoardRevision != "" {
+		table = append(table, Field{Key: "B", Value: otp.BoardRevision})
+	}
+
+	return append(table, otp.Extra...)
+}
+
+func (otp Otp) Encode() ([]byte, error) {
+	return otp.Table().Encode(OtpSize)
+}
+
+// Calibration is the flash region libbladeRF reads at open time for the
+// VCTCXO DAC trim and, on bladeRF 1, the FPGA size ("40" or "115").
+type Calibration struct {
+	FpgaSize string
+	DacTrim  uint16
+	Extra    FieldTable
+}
+
+func ParseCalibration(buf []byte) (Calibration, error) {
+	table, err := ParseFieldTable(buf)
+
+	if err != nil {
+		return Calibration{}, err
+	}
+
+	var calibration Calibration
+	err = codeError(exception.Inval, "parse_calibration", "DAC", "missing")
+
+	for _, field := range table {
+		switch field.Key {
+		case "B":
+			calibration.FpgaSize = field.Value
+		case "DAC":
+			if trim, parseErr := strconv.ParseUint(field.Value, 0, 16); parseErr == nil {
+				calibration.DacTrim = uint16(trim)
+				err = nil
+			} else {
+				err = codeError(exception.Inval, "parse_calibration", "DAC", field.Value)
+			}
+		default:
+			calibration.Extra = append(calibration.Extra, field)
+		}
+	}
+
+	return calibration, err
+}
+
+func (calibration Calibration) Table() FieldTable {
+	var table FieldTable
+
+	if calibration.FpgaSize != "" {
+		table = append(table, Field{Key: "B", Value: calibration.FpgaSize})
+	}
+
+	table = append(table, Field{Key: "DAC", Value: strconv.Itoa(int(calibration.DacTrim))})
+	return append(table, calibration.Extra...)
+}
+
+func (calibration Calibration) Encode() ([]byte, error) {
+	return calibration.Table().Encode(CalibrationSize)
+}
+
+// checkFields encodes a table and parses the result back, so a region is
+// never written unless libbladeRF will be able to read every field of it.
+func checkFields(table FieldTable, size int) ([]byte, error) {
+	buf, err := table.Encode(size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := ParseFieldTable(buf)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if parsed.String() != table.String() {
+		return nil, codeError(exception.Checksum, "check_fields", "fields", table)
+	}
+
+	return buf, nil
+}
+
+func describeWrite(dryRun io.Writer, operation string, table FieldTable, buf []byte) {
+	used := len(buf)
+
+	for used > 0 && buf[used-1] == 0xff {
+		used--
+	}
+
+	fmt.Fprintf(dryRun, "%s (dry run): %s\n%s", operation, table, hex.Dump(buf[:used]))
+}
+
+func readCalibration(device Device) (Calibration, error) {
+	buf, err := device.ReadFlashBytes(CalibrationAddress, CalibrationSize)
+
+	if err != nil {
+		return Calibration{}, err
+	}
+
+	return ParseCalibration(buf)
+}
+
+// writeCalibration rewrites the erase block holding the calibration region,
+// keeping the rest of the block, and reads it back.
+func writeCalibration(device Device, calibration Calibration, dryRun io.Writer) error {
+	buf, err := checkFields(calibration.Table(), CalibrationSize)
+
+	if err != nil {
+		return err
+	}
+
+	if dryRun != nil {
+		describeWrite(dryRun, fmt.Sprintf("write_flash addr=0x%08x", CalibrationAddress), calibration.Table(), buf)
+		return nil
+	}
+
+	block, err := device.ReadFlashBytes(CalibrationAddress, FlashEraseBlockSize)
+
+	if err != nil {
+		return err
+	}
+
+	copy(block, buf)
+
+	if err = device.EraseFlashBytes(CalibrationAddress, FlashEraseBlockSize); err != nil {
+		return err
+	}
+
+	if err = device.WriteFlashBytes(block, CalibrationAddress, FlashEraseBlockSize); err != nil {
+		return err
+	}
+
+	written, err := device.ReadFlashBytes(CalibrationAddress, CalibrationSize)
+
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(written, buf) {
+		return codeError(exception.Io, "write_calibration", "addr", fmt.Sprintf("0x%08x", CalibrationAddress))
+	}
+
+	return nil
+}
+
+// storeVctcxoTrim applies trim to the DAC immediately and stores it in the
+// calibration region so libbladeRF loads it on the next open.
+func storeVctcxoTrim(device Device, trim uint16, dryRun io.Writer) error {
+	calibration, err := readCalibration(device)
+
+	if err != nil && !errors.Is(err, exception.Inval) {
+		return err
+	}
+
+	calibration.DacTrim = trim
+
+	if dryRun == nil {
+		if err = device.TrimDacWrite(trim); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintf(dryRun, "trim_dac_write (dry run): val=0x%04x\n", trim)
+	}
+
+	return writeCalibration(device, calibration, dryRun)
+}
+
+func readOtpFields(device Device) (Otp, error) {
+	buf, err := device.ReadOtp()
+
+	if err != nil {
+		return Otp{}, err
+	}
+
+	return ParseOtp(buf)
+}
+
+// writeOtpFields refuses to write unless the new table is valid and every
+// bit it needs can still be programmed, as OTP bits only go from 1 to 0.
+func writeOtpFields(device Device, otp Otp, dryRun io.Writer) error {
+	buf, err := checkFields(otp.Table(), OtpSize)
+
+	if err != nil {
+		return err
+	}
+
+	current, err := device.ReadOtp()
+
+	if err != nil {
+		return err
+	}
+
+	for i := range buf {
+		if i < len(current) && buf[i]&^current[i] != 0 {
+			return codeError(exception.Permission, "write_otp", "offset", i)
+		}
+	}
+
+	if dryRun != nil {
+		describeWrite(dryRun, "write_otp", otp.Table(), buf)
+		return nil
+	}
+
+	return device.WriteOtp(buf)
+}
+
+func lockOtpFields(device Device, dryRun io.Writer) error {
+	otp, err := readOtpFields(device)
+
+	if err != nil {
+		return err
+	}
+
+	if dryRun != nil {
+		fmt.Fprintf(dryRun, "lock_otp (dry run): %s\n", otp.Table())
+		return nil
+	}
+
+	return device.LockOtp()
+}
+
+func (bladeRF *BladeRF) ReadCalibration() (Calibration, error) {
+	return readCalibration(bladeRF)
+}
+
+// WriteCalibration stores calibration in flash. When dryRun is not nil the
+// device is left untouched and the fields and bytes that would be written
+// are described to it instead.
+func (bladeRF *BladeRF) WriteCalibration(calibration Calibration, dryRun io.Writer) error {
+	return writeCalibration(bladeRF, calibration, dryRun)
+}
+
+// StoreVctcxoTrim sets the VCTCXO trim DAC and persists the value in the
+// calibration region, keeping the other fields stored there.
+func (bladeRF *BladeRF) StoreVctcxoTrim(trim uint16, dryRun io.Writer) error {
+	return storeVctcxoTrim(bladeRF, trim, dryRun)
+}
+
+func (bladeRF *BladeRF) ReadOtpFields() (Otp, error) {
+	return readOtpFields(bladeRF)
+}
+
+// WriteOtpFields programs the OTP field table. The write is rejected if it
+// would need to set a bit that has already been cleared.
+func (bladeRF *BladeRF) WriteOtpFields(otp Otp, dryRun io.Writer) error {
+	return writeOtpFields(bladeRF, otp, dryRun)
+}
+
+// LockOtpFields locks the OTP, but only once its contents parse with valid
+// CRCs. Locking is permanent.
+func (bladeRF *BladeRF) LockOtpFields(dryRun io.Writer) error {
+	return lockOtpFields(bladeRF, dryRun)
+}
+
+func (simulator *Simulator) ReadCalibration() (Calibration, error) {
+	return readCalibration(simulator)
+}
+
+func (simulator *Simulator) WriteCalibration(calibration Calibration, dryRun io.Writer) error {
+	return writeCalibration(simulator, calibration, dryRun)
+}
+
+func (simulator *Simulator) StoreVctcxoTrim(trim uint16, dryRun io.Writer) error {
+	return storeVctcxoTrim(simulator, trim, dryRun)
+}
+
+func (simulator *Simulator) ReadOtpFields() (Otp, error) {
+	return readOtpFields(simulator)
+}
+
+func (simulator *Simulator) WriteOtpFields(otp Otp, dryRun io.Writer) error {
+	return writeOtpFields(simulator, otp, dryRun)
+}
+
+func (simulator *Simulator) LockOtpFields(dryRun io.Writer) error {
+	return lockOtpFields(simulator, dryRun)
+}
@@ -0,0 +1,123 @@
+package bladerf
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/erayarslan/go-bladerf/flashimage"
+)
+
+const flashChunkPages = FlashEraseBlockSize / FlashPageSize
+
+func backupFlash(device Device, path string) error {
+	size, _, err := device.GetFpgaFlashSize()
+
+	if err != nil {
+		return err
+	}
+
+	serial, err := device.GetSerialStruct()
+
+	if err != nil {
+		return err
+	}
+
+	data := make([]byte, 0, size)
+
+	for page := uint32(0); page < size/FlashPageSize; page += flashChunkPages {
+		chunk, err := device.ReadFlash(page, flashChunkPages)
+
+		if err != nil {
+			return err
+		}
+
+		data = append(data, chunk...)
+	}
+
+	image := flashimage.New(flashimage.TypeRaw, 0, data)
+	image.Serial = serial.Serial
+	return image.Write(path)
+}
+
+// restoreFlash erases, writes and reads back one erase block at a time so a
+// failure leaves at most a single block in an unknown state.
+func restoreFlash(device Device, path string) error {
+	image, err := flashimage.Read(path)
+
+	if err != nil {
+		return err
+	}
+
+	if image.Type != flashimage.TypeRaw {
+		return fmt.Errorf("%s: not a raw flash image (type %d)", path, image.Type)
+	}
+
+	if image.Address%FlashEraseBlockSize != 0 || len(image.Data)%FlashEraseBlockSize != 0 {
+		return fmt.Errorf("%s: image is not aligned to %d byte erase blocks", path, FlashEraseBlockSize)
+	}
+
+	size, _, err := device.GetFpgaFlashSize()
+
+	if err != nil {
+		return err
+	}
+
+	if uint64(image.Address)+uint64(len(image.Data)) > uint64(size) {
+		return fmt.Errorf("%s: image of %d bytes at 0x%08x exceeds %d byte flash", path, len(image.Data), image.Address, size)
+	}
+
+	serial, err := device.GetSerialStruct()
+
+	if err != nil {
+		return err
+	}
+
+	if image.Serial != "" && image.Serial != serial.Serial {
+		return fmt.Errorf("%s: backup belongs to device %s, not %s", path, image.Serial, serial.Serial)
+	}
+
+	for offset := 0; offset < len(image.Data); offset += FlashEraseBlockSize {
+		address := image.Address + uint32(offset)
+		block := image.Data[offset : offset+FlashEraseBlockSize]
+
+		if err = device.EraseFlash(address/FlashEraseBlockSize, 1); err != nil {
+			return err
+		}
+
+		if err = device.WriteFlash(block, address/FlashPageSize, flashChunkPages); err != nil {
+			return err
+		}
+
+		written, err := device.ReadFlash(address/FlashPageSize, flashChunkPages)
+
+		if err != nil {
+			return err
+		}
+
+		if !bytes.Equal(written, block) {
+			return fmt.Errorf("flash verification failed at 0x%08x", address)
+		}
+	}
+
+	return nil
+}
+
+// BackupFlash reads the entire SPI flash and stores it at path as a raw
+// bladerf_image tagged with the device serial.
+func (bladeRF *BladeRF) BackupFlash(path string) error {
+	return backupFlash(bladeRF, path)
+}
+
+// RestoreFlash writes a backup made by BackupFlash back to the device,
+// verifying each erase block. It refuses images taken from another device,
+// as the flash holds per-board calibration data.
+func (bladeRF *BladeRF) RestoreFlash(path string) error {
+	return restoreFlash(bladeRF, path)
+}
+
+func (simulator *Simulator) BackupFlash(path string) error {
+	return backupFlash(simulator, path)
+}
+
+func (simulator *Simulator) RestoreFlash(path string) error {
+	return restoreFlash(simulator, path)
+}
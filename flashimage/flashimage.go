@@ -0,0 +1,212 @@
+// Package flashimage reads and writes the container libbladeRF uses for
+// flash contents (bladerf_image_read/bladerf_image_write) without cgo.
+//
+// All multi-byte fields are big endian and the SHA256 checksum is taken over
+// the whole image with the checksum field set to zero:
+//
+//	offset  size  field
+//	0       7     magic "bladeRF"
+//	7       32    SHA256 checksum
+//	39      6     format version (major, minor, patch)
+//	45      8     timestamp, seconds since the epoch
+//	53      33    serial number, NUL terminated
+//	86      128   reserved
+//	214     4     type
+//	218     4     flash address
+//	222     4     data length
+//	226     n     data
+package flashimage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+)
+
+const (
+	Magic          = "bladeRF"
+	MagicLength    = 7
+	ChecksumLength = 32
+	SerialLength   = 33
+	ReservedLength = 128
+	HeaderSize     = MagicLength + ChecksumLength + 3*2 + 8 + SerialLength + ReservedLength + 3*4
+
+	checksumEnd = MagicLength + ChecksumLength
+)
+
+type Type int32
+
+const (
+	TypeInvalid     Type = -1
+	TypeRaw         Type = 0
+	TypeFirmware    Type = 1
+	TypeFpga40kle   Type = 2
+	TypeFpga115kle  Type = 3
+	TypeFpgaA4      Type = 4
+	TypeFpgaA9      Type = 5
+	TypeCalibration Type = 6
+	TypeRxDcCal     Type = 7
+	TypeTxDcCal     Type = 8
+	TypeRxIqCal     Type = 9
+	TypeTxIqCal     Type = 10
+	TypeFpgaA5      Type = 11
+)
+
+type Version struct {
+	Major uint16
+	Minor uint16
+	Patch uint16
+}
+
+// CurrentVersion is the format version libbladeRF writes.
+var CurrentVersion = Version{Major: 0, Minor: 1, Patch: 0}
+
+var (
+	ErrMagic     = errors.New("flashimage: bad magic")
+	ErrChecksum  = errors.New("flashimage: checksum mismatch")
+	ErrTruncated = errors.New("flashimage: truncated image")
+)
+
+type Image struct {
+	Version   Version
+	Timestamp time.Time
+	Serial    string
+	Type      Type
+	Address   uint32
+	Data      []byte
+	reserved  [ReservedLength]byte
+}
+
+// New returns an image of the given type stamped with the current time.
+func New(imageType Type, address uint32, data []byte) *Image {
+	return &Image{
+		Version:   CurrentVersion,
+		Timestamp: time.Now(),
+		Type:      imageType,
+		Address:   address,
+		Data:      data,
+	}
+}
+
+func checksum(buf []byte) [ChecksumLength]byte {
+	zeroed := append([]byte(nil), buf...)
+
+	for i := MagicLength; i < checksumEnd; i++ {
+		zeroed[i] = 0
+	}
+
+	return sha256.Sum256(zeroed)
+}
+
+// Encode serializes the image and fills in its checksum.
+func (image *Image) Encode() ([]byte, error) {
+	if len(image.Serial) >= SerialLength {
+		return nil, fmt.Errorf("flashimage: serial %q longer than %d characters", image.Serial, SerialLength-1)
+	}
+
+	if uint64(len(image.Data)) > uint64(^uint32(0)) {
+		return nil, fmt.Errorf("flashimage: %d bytes of data do not fit in an image", len(image.Data))
+	}
+
+	buf := make([]byte, HeaderSize+len(image.Data))
+	offset := copy(buf, Magic) + ChecksumLength
+
+	binary.BigEndian.PutUint16(buf[offset:], image.Version.Major)
+	binary.BigEndian.PutUint16(buf[offset+2:], image.Version.Minor)
+	binary.BigEndian.PutUint16(buf[offset+4:], image.Version.Patch)
+	offset += 6
+
+	if !image.Timestamp.IsZero() {
+		binary.BigEndian.PutUint64(buf[offset:], uint64(image.Timestamp.Unix()))
+	}
+
+	offset += 8
+	copy(buf[offset:], image.Serial)
+	offset += SerialLength
+	offset += copy(buf[offset:], image.reserved[:])
+
+	binary.BigEndian.PutUint32(buf[offset:], uint32(image.Type))
+	binary.BigEndian.PutUint32(buf[offset+4:], image.Address)
+	binary.BigEndian.PutUint32(buf[offset+8:], uint32(len(image.Data)))
+	copy(buf[HeaderSize:], image.Data)
+
+	sum := checksum(buf)
+	copy(buf[MagicLength:], sum[:])
+
+	return buf, nil
+}
+
+// Decode parses an image and verifies its magic, checksum and length.
+func Decode(buf []byte) (*Image, error) {
+	if len(buf) < HeaderSize {
+		return nil, ErrTruncated
+	}
+
+	if string(buf[:MagicLength]) != Magic {
+		return nil, ErrMagic
+	}
+
+	if sum := checksum(buf); !bytes.Equal(sum[:], buf[MagicLength:checksumEnd]) {
+		return nil, ErrChecksum
+	}
+
+	image := &Image{}
+	offset := checksumEnd
+
+	image.Version.Major = binary.BigEndian.Uint16(buf[offset:])
+	image.Version.Minor = binary.BigEndian.Uint16(buf[offset+2:])
+	image.Version.Patch = binary.BigEndian.Uint16(buf[offset+4:])
+	offset += 6
+
+	if seconds := binary.BigEndian.Uint64(buf[offset:]); seconds != 0 {
+		image.Timestamp = time.Unix(int64(seconds), 0)
+	}
+
+	offset += 8
+	serial := buf[offset : offset+SerialLength]
+
+	if end := bytes.IndexByte(serial, 0); end >= 0 {
+		serial = serial[:end]
+	}
+
+	image.Serial = string(serial)
+	offset += SerialLength
+	offset += copy(image.reserved[:], buf[offset:])
+
+	image.Type = Type(binary.BigEndian.Uint32(buf[offset:]))
+	image.Address = binary.BigEndian.Uint32(buf[offset+4:])
+	length := binary.BigEndian.Uint32(buf[offset+8:])
+
+	if uint64(len(buf)-HeaderSize) < uint64(length) {
+		return nil, ErrTruncated
+	} else if uint64(len(buf)-HeaderSize) != uint64(length) {
+		return nil, fmt.Errorf("flashimage: header declares %d bytes of data, found %d", length, len(buf)-HeaderSize)
+	}
+
+	image.Data = buf[HeaderSize:]
+	return image, nil
+}
+
+func Read(path string) (*Image, error) {
+	buf, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return Decode(buf)
+}
+
+func (image *Image) Write(path string) error {
+	buf, err := image.Encode()
+
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, buf, 0644)
+}
@@ -0,0 +1,73 @@
+package flashimage
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+	"time"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	image := New(TypeCalibration, 0x30000, []byte{1, 2, 3, 4})
+	image.Serial = "0123456789abcdef0123456789abcdef"
+	image.Timestamp = time.Unix(1600000000, 0)
+	buf, err := image.Encode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := Decode(buf)
+
+	if err == nil && len(buf) == HeaderSize+4 && decoded.Serial == image.Serial && decoded.Type == TypeCalibration &&
+		decoded.Address == 0x30000 && decoded.Version == CurrentVersion && decoded.Timestamp.Equal(image.Timestamp) &&
+		bytes.Equal(decoded.Data, image.Data) {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v %+v", err, decoded)
+	}
+
+	if string(buf[:MagicLength]) == "bladeRF" && buf[HeaderSize-1] == 4 && buf[HeaderSize-12] == 0 && buf[HeaderSize-9] == 6 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got header % x", buf[HeaderSize-12:HeaderSize])
+	}
+
+	// SHA256 of the same image with the checksum field zeroed, as written by
+	// libbladeRF.
+	const golden = "cd54c350eca404af4048cc505287016328567c03f60498e00343845e7239c1b3"
+
+	if sum := hex.EncodeToString(buf[MagicLength:checksumEnd]); sum == golden {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got checksum %s", sum)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	buf, _ := New(TypeRaw, 0, []byte{1, 2, 3, 4}).Encode()
+
+	corrupt := append([]byte(nil), buf...)
+	corrupt[HeaderSize] ^= 0xff
+
+	if _, err := Decode(corrupt); err == ErrChecksum {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err)
+	}
+
+	corrupt = append([]byte(nil), buf...)
+	corrupt[0] = 'B'
+
+	if _, err := Decode(corrupt); err == ErrMagic {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err)
+	}
+
+	if _, err := Decode(buf[:HeaderSize-1]); err == ErrTruncated {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err)
+	}
+}
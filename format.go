@@ -0,0 +1,67 @@
+package bladerf
+
+import (
+	"encoding/binary"
+	"github.com/erayarslan/go-bladerf/iq"
+	"unsafe"
+)
+
+// SampleSize returns the size in bytes of one interleaved I/Q pair.
+func (format Format) SampleSize() int {
+	if format == FormatSc8Q7 || format == FormatSc8Q7Meta {
+		return 2
+	}
+
+	return 4
+}
+
+func (format Format) HasMetadata() bool {
+	return format == FormatSc16Q11Meta || format == FormatSc8Q7Meta
+}
+
+// FullScale returns the integer value that corresponds to 1.0 in format.
+func (format Format) FullScale() float32 {
+	if format.SampleSize() == 2 {
+		return iq.Sc8Q7FullScale
+	}
+
+	return iq.Sc16Q11FullScale
+}
+
+func Sc16Q11ToComplex64(input []int16) []complex64 {
+	var complexFloat = make([]complex64, len(input)/2)
+	iq.Sc16Q11ToComplex64(complexFloat, input)
+	return complexFloat
+}
+
+func Sc8Q7ToComplex64(input []int8) []complex64 {
+	var complexFloat = make([]complex64, len(input)/2)
+	iq.Sc8Q7ToComplex64(complexFloat, input)
+	return complexFloat
+}
+
+// BytesToComplex64 decodes an interleaved little endian byte view of samples
+// in format, as read from a capture file or a raw stream buffer.
+func (format Format) BytesToComplex64(input []byte) []complex64 {
+	if format.SampleSize() == 2 {
+		return Sc8Q7ToComplex64(Int8View(input))
+	}
+
+	samples := make([]int16, len(input)/2)
+
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(input[2*i:]))
+	}
+
+	return Sc16Q11ToComplex64(samples)
+}
+
+// Int8View reinterprets an interleaved SC8_Q7 byte buffer as []int8 without
+// copying.
+func Int8View(input []byte) []int8 {
+	if len(input) == 0 {
+		return nil
+	}
+
+	return (*[1 << 30]int8)(unsafe.Pointer(&input[0]))[:len(input):len(input)]
+}
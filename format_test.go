@@ -0,0 +1,32 @@
+package bladerf
+
+import (
+	"github.com/erayarslan/go-bladerf/iq"
+	"testing"
+)
+
+func TestFormatConversion(t *testing.T) {
+	wide := []int16{2047, -2048, 1024, -16}
+	narrow := make([]int8, len(wide))
+	iq.Sc16Q11ToSc8Q7(narrow, wide)
+
+	if narrow[0] == 127 && narrow[1] == -128 && narrow[2] == 64 && narrow[3] == -1 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", narrow)
+	}
+
+	samples := FormatSc16Q11.BytesToComplex64([]byte{0x00, 0x04, 0x00, 0xfc})
+
+	if len(samples) == 1 && samples[0] == complex(0.5, -0.5) {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", samples)
+	}
+
+	if FormatSc8Q7Meta.SampleSize() == 2 && FormatSc8Q7Meta.HasMetadata() && !FormatSc16Q11.HasMetadata() {
+		t.Log("PASSED")
+	} else {
+		t.Error("FAILED cause wrong format properties")
+	}
+}
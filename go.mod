@@ -2,4 +2,7 @@ module github.com/erayarslan/go-bladerf
 
 go 1.13
 
-require github.com/mattn/go-pointer v0.0.1
+require (
+	github.com/mattn/go-pointer v0.0.1
+	gopkg.in/yaml.v2 v2.4.0
+)
@@ -0,0 +1,156 @@
+package bladerf
+
+// ExpansionPin numbers a GPIO of the expansion header, matching
+// BLADERF_XB_GPIO_01 to BLADERF_XB_GPIO_32.
+type ExpansionPin uint8
+
+const (
+	ExpansionPin01 ExpansionPin = iota + 1
+	ExpansionPin02
+	ExpansionPin03
+	ExpansionPin04
+	ExpansionPin05
+	ExpansionPin06
+	ExpansionPin07
+	ExpansionPin08
+	ExpansionPin09
+	ExpansionPin10
+	ExpansionPin11
+	ExpansionPin12
+	ExpansionPin13
+	ExpansionPin14
+	ExpansionPin15
+	ExpansionPin16
+	ExpansionPin17
+	ExpansionPin18
+	ExpansionPin19
+	ExpansionPin20
+	ExpansionPin21
+	ExpansionPin22
+	ExpansionPin23
+	ExpansionPin24
+	ExpansionPin25
+	ExpansionPin26
+	ExpansionPin27
+	ExpansionPin28
+	ExpansionPin29
+	ExpansionPin30
+	ExpansionPin31
+	ExpansionPin32
+)
+
+// Mask returns the bit of pin in the expansion GPIO registers.
+func (pin ExpansionPin) Mask() uint32 {
+	return 1 << (pin - 1)
+}
+
+// GPIOPin is a single digital line.
+type GPIOPin interface {
+	Set(high bool) error
+	Get() (bool, error)
+	Toggle() error
+}
+
+// ExpansionGPIO drives the GPIOs of the expansion header. Every change goes
+// through a masked write, so pins other than the ones named are left alone.
+type ExpansionGPIO struct {
+	device Device
+}
+
+func NewExpansionGPIO(device Device) *ExpansionGPIO {
+	return &ExpansionGPIO{device: device}
+}
+
+func (gpio *ExpansionGPIO) Read() (uint32, error) {
+	return gpio.device.ExpansionGpioRead()
+}
+
+func (gpio *ExpansionGPIO) Write(val uint32) error {
+	return gpio.device.ExpansionGpioWrite(val)
+}
+
+func (gpio *ExpansionGPIO) MaskedWrite(mask uint32, val uint32) error {
+	return gpio.device.ExpansionGpioMaskedWrite(mask, val)
+}
+
+// Outputs returns the direction register, with a bit set for every output.
+func (gpio *ExpansionGPIO) Outputs() (uint32, error) {
+	return gpio.device.ExpansionGpioDirRead()
+}
+
+func (gpio *ExpansionGPIO) SetOutputs(outputs uint32) error {
+	return gpio.device.ExpansionGpioDirWrite(outputs)
+}
+
+func (gpio *ExpansionGPIO) MaskedSetOutputs(mask uint32, outputs uint32) error {
+	return gpio.device.ExpansionGpioDirMaskedWrite(mask, outputs)
+}
+
+func (gpio *ExpansionGPIO) SetOutput(pin ExpansionPin, output bool) error {
+	var outputs uint32
+
+	if output {
+		outputs = pin.Mask()
+	}
+
+	return gpio.MaskedSetOutputs(pin.Mask(), outputs)
+}
+
+func (gpio *ExpansionGPIO) IsOutput(pin ExpansionPin) (bool, error) {
+	outputs, err := gpio.Outputs()
+
+	if err != nil {
+		return false, err
+	}
+
+	return outputs&pin.Mask() != 0, nil
+}
+
+// Pin returns a handle for a single pin. It does not change its direction.
+func (gpio *ExpansionGPIO) Pin(pin ExpansionPin) Pin {
+	return Pin{gpio: gpio, Number: pin}
+}
+
+// Pin is one GPIO of the expansion header.
+type Pin struct {
+	gpio   *ExpansionGPIO
+	Number ExpansionPin
+}
+
+var _ GPIOPin = Pin{}
+
+func (pin Pin) Set(high bool) error {
+	var val uint32
+
+	if high {
+		val = pin.Number.Mask()
+	}
+
+	return pin.gpio.MaskedWrite(pin.Number.Mask(), val)
+}
+
+func (pin Pin) Get() (bool, error) {
+	val, err := pin.gpio.Read()
+
+	if err != nil {
+		return false, err
+	}
+
+	return val&pin.Number.Mask() != 0, nil
+}
+
+// Toggle inverts the pin. The read and the write are separate transfers, so
+// concurrent changes to the same pin from elsewhere may be lost.
+func (pin Pin) Toggle() error {
+	high, err := pin.Get()
+
+	if err != nil {
+		return err
+	}
+
+	return pin.Set(!high)
+}
+
+func (pin Pin) SetOutput(output bool) error {
+	return pin.gpio.SetOutput(pin.Number, output)
+}
@@ -0,0 +1,225 @@
+package bladerf
+
+import (
+	"context"
+	"errors"
+	exception "github.com/erayarslan/go-bladerf/error"
+	"sync"
+	"time"
+)
+
+// RetuneQueueDepth is the number of retunes the FPGA can hold per board.
+const RetuneQueueDepth = 16
+
+const (
+	hopperBackoff = time.Millisecond
+	hopperHistory = 1024
+)
+
+// Hop is a retune made by a Hopper. Timestamp is when the retune takes
+// effect. Hops whose time passes before they can be queued are skipped
+// rather than sent late.
+type Hop struct {
+	Index     int
+	Frequency uint64
+	Timestamp Timestamp
+}
+
+// Hopper cycles a channel through a list of frequencies, one every Dwell
+// samples, using quick tune entries computed up front and the FPGA retune
+// queue.
+type Hopper struct {
+	device        Device
+	channel       Channel
+	direction     Direction
+	frequencies   []uint64
+	quickTunes    []QuickTune
+	Dwell         Timestamp
+	dwellDuration time.Duration
+
+	mu   sync.Mutex
+	hops []Hop
+}
+
+// NewHopper tunes channel to each frequency in turn to record its quick
+// tune entry, then puts the channel back on its original frequency. dwell is
+// converted to samples at the channel's current sample rate.
+func NewHopper(device Device, channel Channel, frequencies []uint64, dwell time.Duration) (*Hopper, error) {
+	rate, err := device.GetRationalSampleRate(channel)
+
+	if err != nil {
+		return nil, err
+	}
+
+	hopper := &Hopper{
+		device:        device,
+		channel:       channel,
+		direction:     Rx,
+		frequencies:   frequencies,
+		quickTunes:    make([]QuickTune, len(frequencies)),
+		Dwell:         durationToTicks(dwell, rate),
+		dwellDuration: dwell,
+	}
+
+	if ChannelIsTx(int(channel)) {
+		hopper.direction = Tx
+	}
+
+	if len(frequencies) == 0 || hopper.Dwell == 0 {
+		return nil, exception.NewWithOperation(int(exception.Inval), "new_hopper", formatArguments("ch", channel, "dwell", dwell))
+	}
+
+	original, err := device.GetFrequency(channel)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i, frequency := range frequencies {
+		if err = device.SetFrequency(channel, frequency); err != nil {
+			return nil, err
+		}
+
+		if hopper.quickTunes[i], err = device.GetQuickTune(channel); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = device.SetFrequency(channel, original); err != nil {
+		return nil, err
+	}
+
+	return hopper, nil
+}
+
+// Run schedules hop i at start + i*Dwell until ctx is done, keeping at most
+// RetuneQueueDepth retunes outstanding. Hops already in the past, including
+// all those before now when start is, are skipped. If the FPGA still reports
+// the queue full, Run waits and retries with an exponential backoff capped at
+// one dwell. Retunes not yet reached are cancelled on return.
+func (hopper *Hopper) Run(ctx context.Context, start Timestamp) error {
+	maxBackoff := hopper.dwellDuration
+
+	if maxBackoff < hopperBackoff {
+		maxBackoff = hopperBackoff
+	}
+
+	backoff := hopperBackoff
+
+	for i := 0; ; {
+		select {
+		case <-ctx.Done():
+			return hopper.stop(ctx.Err())
+		default:
+		}
+
+		now, err := hopper.device.GetTimestamp(hopper.direction)
+
+		if err != nil {
+			return hopper.stop(err)
+		}
+
+		if scheduled := start + Timestamp(i)*hopper.Dwell; scheduled <= now {
+			i = int((now-start)/hopper.Dwell) + 1
+		}
+
+		scheduled := start + Timestamp(i)*hopper.Dwell
+
+		if hopper.outstanding(now) < RetuneQueueDepth {
+			index := i % len(hopper.frequencies)
+			err = hopper.device.ScheduleReTune(hopper.channel, scheduled, hopper.frequencies[index], hopper.quickTunes[index])
+
+			if err == nil {
+				hopper.record(Hop{Index: index, Frequency: hopper.frequencies[index], Timestamp: scheduled})
+				backoff = hopperBackoff
+				i++
+				continue
+			} else if !errors.Is(err, exception.QueueFull) {
+				return hopper.stop(err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return hopper.stop(ctx.Err())
+		case <-time.After(backoff):
+		}
+
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+func (hopper *Hopper) outstanding(now Timestamp) int {
+	hopper.mu.Lock()
+	defer hopper.mu.Unlock()
+
+	count := 0
+
+	for i := len(hopper.hops) - 1; i >= 0 && hopper.hops[i].Timestamp > now; i-- {
+		count++
+	}
+
+	return count
+}
+
+func (hopper *Hopper) record(hop Hop) {
+	hopper.mu.Lock()
+	defer hopper.mu.Unlock()
+
+	if len(hopper.hops) == hopperHistory {
+		hopper.hops = append(hopper.hops[:0], hopper.hops[1:]...)
+	}
+
+	hopper.hops = append(hopper.hops, hop)
+}
+
+// stop cancels the pending retunes and drops them from the history, since
+// they will no longer happen.
+func (hopper *Hopper) stop(err error) error {
+	cancelErr := hopper.device.CancelScheduledReTunes(hopper.channel)
+	now, timestampErr := hopper.device.GetTimestamp(hopper.direction)
+
+	if timestampErr == nil {
+		hopper.mu.Lock()
+
+		for len(hopper.hops) > 0 && hopper.hops[len(hopper.hops)-1].Timestamp > now {
+			hopper.hops = hopper.hops[:len(hopper.hops)-1]
+		}
+
+		hopper.mu.Unlock()
+	}
+
+	if cancelErr != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		return cancelErr
+	}
+
+	return err
+}
+
+// Hops returns the most recent hops, oldest first.
+func (hopper *Hopper) Hops() []Hop {
+	hopper.mu.Lock()
+	defer hopper.mu.Unlock()
+
+	return append([]Hop(nil), hopper.hops...)
+}
+
+// HopsIn returns the hops taking effect within the buffer described by RX
+// metadata, so samples can be attributed to the right frequency.
+func (hopper *Hopper) HopsIn(metadata Metadata) []Hop {
+	hopper.mu.Lock()
+	defer hopper.mu.Unlock()
+
+	var hops []Hop
+	end := metadata.Timestamp + Timestamp(metadata.ActualCount)
+
+	for _, hop := range hopper.hops {
+		if hop.Timestamp >= metadata.Timestamp && hop.Timestamp < end {
+			hops = append(hops, hop)
+		}
+	}
+
+	return hops
+}
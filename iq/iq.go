@@ -0,0 +1,224 @@
+// Package iq converts between the bladeRF wire formats and floating point
+// I/Q samples. Every function writes into a caller supplied destination and
+// returns the number of complex samples converted, so none of them allocate.
+package iq
+
+const (
+	Sc16Q11FullScale = 2048
+	Sc8Q7FullScale   = 128
+)
+
+func count(dst int, src int) int {
+	if dst < src {
+		return dst
+	}
+
+	return src
+}
+
+func saturate16(value float64) int16 {
+	value *= Sc16Q11FullScale
+
+	if value >= Sc16Q11FullScale-1 {
+		return Sc16Q11FullScale - 1
+	} else if value <= -Sc16Q11FullScale {
+		return -Sc16Q11FullScale
+	} else if value != value {
+		return 0
+	} else if value < 0 {
+		return int16(value - 0.5)
+	}
+
+	return int16(value + 0.5)
+}
+
+func saturate8(value float64) int8 {
+	value *= Sc8Q7FullScale
+
+	if value >= Sc8Q7FullScale-1 {
+		return Sc8Q7FullScale - 1
+	} else if value <= -Sc8Q7FullScale {
+		return -Sc8Q7FullScale
+	} else if value != value {
+		return 0
+	} else if value < 0 {
+		return int8(value - 0.5)
+	}
+
+	return int8(value + 0.5)
+}
+
+// clip16 limits a received value to the 12-bit range of the SC16Q11 format.
+func clip16(value int16) int16 {
+	if value > Sc16Q11FullScale-1 {
+		return Sc16Q11FullScale - 1
+	} else if value < -Sc16Q11FullScale {
+		return -Sc16Q11FullScale
+	}
+
+	return value
+}
+
+func Sc16Q11ToComplex64(dst []complex64, src []int16) int {
+	n := count(len(dst), len(src)/2)
+	src = src[:n*2]
+
+	for i := range dst[:n] {
+		dst[i] = complex(float32(clip16(src[2*i]))/Sc16Q11FullScale, float32(clip16(src[2*i+1]))/Sc16Q11FullScale)
+	}
+
+	return n
+}
+
+func Sc16Q11ToComplex128(dst []complex128, src []int16) int {
+	n := count(len(dst), len(src)/2)
+	src = src[:n*2]
+
+	for i := range dst[:n] {
+		dst[i] = complex(float64(clip16(src[2*i]))/Sc16Q11FullScale, float64(clip16(src[2*i+1]))/Sc16Q11FullScale)
+	}
+
+	return n
+}
+
+// Sc16Q11ToFloat32 writes interleaved I and Q values into dst.
+func Sc16Q11ToFloat32(dst []float32, src []int16) int {
+	n := count(len(dst)/2, len(src)/2)
+	src = src[:n*2]
+
+	for i, value := range src {
+		dst[i] = float32(clip16(value)) / Sc16Q11FullScale
+	}
+
+	return n
+}
+
+func Complex64ToSc16Q11(dst []int16, src []complex64) int {
+	n := count(len(dst)/2, len(src))
+	dst = dst[:n*2]
+
+	for i, value := range src[:n] {
+		dst[2*i] = saturate16(float64(real(value)))
+		dst[2*i+1] = saturate16(float64(imag(value)))
+	}
+
+	return n
+}
+
+func Complex128ToSc16Q11(dst []int16, src []complex128) int {
+	n := count(len(dst)/2, len(src))
+	dst = dst[:n*2]
+
+	for i, value := range src[:n] {
+		dst[2*i] = saturate16(real(value))
+		dst[2*i+1] = saturate16(imag(value))
+	}
+
+	return n
+}
+
+// Float32ToSc16Q11 reads interleaved I and Q values from src.
+func Float32ToSc16Q11(dst []int16, src []float32) int {
+	n := count(len(dst)/2, len(src)/2)
+	dst = dst[:n*2]
+
+	for i, value := range src[:n*2] {
+		dst[i] = saturate16(float64(value))
+	}
+
+	return n
+}
+
+func Sc8Q7ToComplex64(dst []complex64, src []int8) int {
+	n := count(len(dst), len(src)/2)
+	src = src[:n*2]
+
+	for i := range dst[:n] {
+		dst[i] = complex(float32(src[2*i])/Sc8Q7FullScale, float32(src[2*i+1])/Sc8Q7FullScale)
+	}
+
+	return n
+}
+
+func Complex64ToSc8Q7(dst []int8, src []complex64) int {
+	n := count(len(dst)/2, len(src))
+	dst = dst[:n*2]
+
+	for i, value := range src[:n] {
+		dst[2*i] = saturate8(float64(real(value)))
+		dst[2*i+1] = saturate8(float64(imag(value)))
+	}
+
+	return n
+}
+
+func Sc8Q7ToComplex128(dst []complex128, src []int8) int {
+	n := count(len(dst), len(src)/2)
+	src = src[:n*2]
+
+	for i := range dst[:n] {
+		dst[i] = complex(float64(src[2*i])/Sc8Q7FullScale, float64(src[2*i+1])/Sc8Q7FullScale)
+	}
+
+	return n
+}
+
+// Sc8Q7ToFloat32 writes interleaved I and Q values into dst.
+func Sc8Q7ToFloat32(dst []float32, src []int8) int {
+	n := count(len(dst)/2, len(src)/2)
+	src = src[:n*2]
+
+	for i, value := range src {
+		dst[i] = float32(value) / Sc8Q7FullScale
+	}
+
+	return n
+}
+
+func Complex128ToSc8Q7(dst []int8, src []complex128) int {
+	n := count(len(dst)/2, len(src))
+	dst = dst[:n*2]
+
+	for i, value := range src[:n] {
+		dst[2*i] = saturate8(real(value))
+		dst[2*i+1] = saturate8(imag(value))
+	}
+
+	return n
+}
+
+// Float32ToSc8Q7 reads interleaved I and Q values from src.
+func Float32ToSc8Q7(dst []int8, src []float32) int {
+	n := count(len(dst)/2, len(src)/2)
+	dst = dst[:n*2]
+
+	for i, value := range src[:n*2] {
+		dst[i] = saturate8(float64(value))
+	}
+
+	return n
+}
+
+func Sc8Q7ToSc16Q11(dst []int16, src []int8) int {
+	n := count(len(dst)/2, len(src)/2)
+	dst = dst[:n*2]
+
+	for i, value := range src[:n*2] {
+		dst[i] = int16(value) << 4
+	}
+
+	return n
+}
+
+// Sc16Q11ToSc8Q7 drops the four least significant bits of every value,
+// saturating anything outside the 12-bit range.
+func Sc16Q11ToSc8Q7(dst []int8, src []int16) int {
+	n := count(len(dst)/2, len(src)/2)
+	dst = dst[:n*2]
+
+	for i, value := range src[:n*2] {
+		dst[i] = int8(clip16(value) >> 4)
+	}
+
+	return n
+}
@@ -0,0 +1,245 @@
+package iq
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSc16Q11ToComplex64(t *testing.T) {
+	src := []int16{1024, -2048, 4000, -4000}
+	dst := make([]complex64, 2)
+	n := Sc16Q11ToComplex64(dst, src)
+
+	if n == 2 && dst[0] == complex(0.5, -1) && real(dst[1]) == 2047.0/2048 && imag(dst[1]) == -1 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", dst)
+	}
+}
+
+func TestComplex64ToSc16Q11(t *testing.T) {
+	src := []complex64{complex(0.5, -0.5), complex(1.5, -1.5), complex(float32(math.NaN()), 0)}
+	dst := make([]int16, 6)
+	n := Complex64ToSc16Q11(dst, src)
+
+	if n == 3 && dst[0] == 1024 && dst[1] == -1024 && dst[2] == 2047 && dst[3] == -2048 && dst[4] == 0 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", dst)
+	}
+}
+
+func TestFloat32RoundTrip(t *testing.T) {
+	src := []int16{0, 1, -1, 2047, -2048, 100}
+	floats := make([]float32, len(src))
+	dst := make([]int16, len(src))
+
+	Sc16Q11ToFloat32(floats, src)
+	n := Float32ToSc16Q11(dst, floats)
+
+	for i := range src {
+		if dst[i] != src[i] {
+			t.Errorf("FAILED cause got %v", dst)
+			return
+		}
+	}
+
+	if n == 3 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", n)
+	}
+}
+
+func TestComplex128ToSc16Q11(t *testing.T) {
+	src := []complex128{complex(-0.25, 0.25)}
+	dst := make([]int16, 2)
+	Complex128ToSc16Q11(dst, src)
+
+	back := make([]complex128, 1)
+	Sc16Q11ToComplex128(back, dst)
+
+	if dst[0] == -512 && dst[1] == 512 && back[0] == src[0] {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", dst)
+	}
+}
+
+func TestSc8Q7(t *testing.T) {
+	dst := make([]int8, 4)
+	Complex64ToSc8Q7(dst, []complex64{complex(0.5, -2), complex(2, 0)})
+
+	if dst[0] == 64 && dst[1] == -128 && dst[2] == 127 && dst[3] == 0 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", dst)
+	}
+
+	wide := make([]int16, 4)
+	Sc8Q7ToSc16Q11(wide, dst)
+	narrow := make([]int8, 4)
+	Sc16Q11ToSc8Q7(narrow, wide)
+
+	if wide[0] == 1024 && narrow[1] == -128 && narrow[2] == 127 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", wide)
+	}
+}
+
+func TestSc8Q7Float(t *testing.T) {
+	dst := make([]int8, 6)
+	n := Complex128ToSc8Q7(dst, []complex128{complex(0.25, -0.25), complex(1.5, -1.5), complex(math.NaN(), 0)})
+
+	if n == 3 && dst[0] == 32 && dst[1] == -32 && dst[2] == 127 && dst[3] == -128 && dst[4] == 0 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", dst)
+	}
+
+	back := make([]complex128, 1)
+	Sc8Q7ToComplex128(back, dst)
+
+	if back[0] == complex(0.25, -0.25) {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", back)
+	}
+
+	src := []int8{0, 1, -1, 127, -128, 100}
+	floats := make([]float32, len(src))
+	narrow := make([]int8, len(src))
+
+	Sc8Q7ToFloat32(floats, src)
+	n = Float32ToSc8Q7(narrow, floats)
+
+	for i := range src {
+		if narrow[i] != src[i] {
+			t.Errorf("FAILED cause got %v", narrow)
+			return
+		}
+	}
+
+	if n == 3 && Float32ToSc8Q7(narrow, []float32{2, -2}) == 1 && narrow[0] == 127 && narrow[1] == -128 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", narrow)
+	}
+}
+
+func TestShortDestination(t *testing.T) {
+	dst := make([]complex64, 1)
+
+	if n := Sc16Q11ToComplex64(dst, make([]int16, 8)); n == 1 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", n)
+	}
+}
+
+var benchmarkSizes = []struct {
+	name string
+	size int
+}{
+	{"1K", 1024},
+	{"16K", 16384},
+	{"256K", 262144},
+	{"1M", 1048576},
+}
+
+func BenchmarkSc16Q11ToComplex64(b *testing.B) {
+	for _, bm := range benchmarkSizes {
+		b.Run(bm.name, func(b *testing.B) {
+			src := make([]int16, bm.size*2)
+			dst := make([]complex64, bm.size)
+			b.SetBytes(int64(len(src) * 2))
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				Sc16Q11ToComplex64(dst, src)
+			}
+		})
+	}
+}
+
+func BenchmarkComplex64ToSc16Q11(b *testing.B) {
+	for _, bm := range benchmarkSizes {
+		b.Run(bm.name, func(b *testing.B) {
+			src := make([]complex64, bm.size)
+			dst := make([]int16, bm.size*2)
+			b.SetBytes(int64(len(dst) * 2))
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				Complex64ToSc16Q11(dst, src)
+			}
+		})
+	}
+}
+
+func BenchmarkSc16Q11ToFloat32(b *testing.B) {
+	for _, bm := range benchmarkSizes {
+		b.Run(bm.name, func(b *testing.B) {
+			src := make([]int16, bm.size*2)
+			dst := make([]float32, bm.size*2)
+			b.SetBytes(int64(len(src) * 2))
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				Sc16Q11ToFloat32(dst, src)
+			}
+		})
+	}
+}
+
+func BenchmarkSc8Q7ToComplex64(b *testing.B) {
+	for _, bm := range benchmarkSizes {
+		b.Run(bm.name, func(b *testing.B) {
+			src := make([]int8, bm.size*2)
+			dst := make([]complex64, bm.size)
+			b.SetBytes(int64(len(src)))
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				Sc8Q7ToComplex64(dst, src)
+			}
+		})
+	}
+}
+
+func BenchmarkSc8Q7ToFloat32(b *testing.B) {
+	for _, bm := range benchmarkSizes {
+		b.Run(bm.name, func(b *testing.B) {
+			src := make([]int8, bm.size*2)
+			dst := make([]float32, bm.size*2)
+			b.SetBytes(int64(len(src)))
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				Sc8Q7ToFloat32(dst, src)
+			}
+		})
+	}
+}
+
+func BenchmarkComplex128ToSc8Q7(b *testing.B) {
+	for _, bm := range benchmarkSizes {
+		b.Run(bm.name, func(b *testing.B) {
+			src := make([]complex128, bm.size)
+			dst := make([]int8, bm.size*2)
+			b.SetBytes(int64(len(dst)))
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				Complex128ToSc8Q7(dst, src)
+			}
+		})
+	}
+}
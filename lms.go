@@ -0,0 +1,149 @@
+package bladerf
+
+import "math"
+
+// LmsReferenceClock is the 38.4 MHz reference of the bladeRF 1 LMS6002D.
+const LmsReferenceClock = 38400000
+
+const lmsRegisterCount = 0x80
+
+// LmsDcCals holds the LMS6002D DC offset calibration values. A field of -1
+// is left unchanged by LmsSetDcCals.
+type LmsDcCals struct {
+	LpfTuning int16
+	TxLpfI    int16
+	TxLpfQ    int16
+	RxLpfI    int16
+	RxLpfQ    int16
+	DcRef     int16
+	RxVga2aI  int16
+	RxVga2aQ  int16
+	RxVga2bI  int16
+	RxVga2bQ  int16
+}
+
+// LmsPll is the state of the TX or RX synthesizer. VtuneHigh and VtuneLow
+// are the VCO tuning voltage comparators; both clear means the PLL is locked.
+type LmsPll struct {
+	Nint      uint16
+	Nfrac     uint32
+	FreqSel   uint8
+	VcoCap    uint8
+	VtuneHigh bool
+	VtuneLow  bool
+}
+
+func (pll LmsPll) Locked() bool {
+	return !pll.VtuneHigh && !pll.VtuneLow
+}
+
+// Frequency returns the LO frequency programmed into the PLL for the given
+// reference clock, normally LmsReferenceClock.
+func (pll LmsPll) Frequency(reference uint64) uint64 {
+	divider := pll.FreqSel & 0x7
+
+	if divider < 4 {
+		return 0
+	}
+
+	vco := (float64(pll.Nint) + float64(pll.Nfrac)/(1<<23)) * float64(reference)
+	return uint64(math.Round(vco / float64(uint(1)<<(divider-3))))
+}
+
+// LmsRegisters decodes the LMS6002D fields most often needed when
+// debugging a bladeRF 1. Bandwidths are RF bandwidths in Hz, as accepted by
+// SetBandwidth, and gains are in dB.
+type LmsRegisters struct {
+	TxEnabled      bool
+	RxEnabled      bool
+	TxPll          LmsPll
+	RxPll          LmsPll
+	TxLpfEnabled   bool
+	TxLpfBandwidth uint
+	RxLpfEnabled   bool
+	RxLpfBandwidth uint
+	TxVga1Gain     int
+	TxVga2Gain     int
+	LnaGain        int
+	RxVga1Gain     int
+	RxVga2Gain     int
+}
+
+var lmsBandwidths = [16]uint{
+	28000000, 20000000, 14000000, 12000000, 10000000, 8750000, 7000000, 6000000,
+	5500000, 5000000, 3840000, 3000000, 2750000, 2500000, 1750000, 1500000,
+}
+
+var lmsLnaGains = [4]int{0, 0, 3, 6}
+
+func decodeLmsPll(registers []uint8) LmsPll {
+	return LmsPll{
+		Nint:      uint16(registers[0])<<1 | uint16(registers[1]>>7),
+		Nfrac:     uint32(registers[1]&0x7f)<<16 | uint32(registers[2])<<8 | uint32(registers[3]),
+		FreqSel:   registers[5] >> 2,
+		VcoCap:    registers[9] & 0x3f,
+		VtuneHigh: registers[10]&0x80 != 0,
+		VtuneLow:  registers[10]&0x40 != 0,
+	}
+}
+
+// rxVga1Gain converts the RXVGA1 feedback resistor code to dB, following
+// the curve libbladeRF uses for its lookup table.
+func rxVga1Gain(code uint8) int {
+	if code > 120 {
+		code = 120
+	}
+
+	return int(math.Round(5 + 20*math.Log10(127/float64(127-code))))
+}
+
+// DecodeLmsRegisters decodes a dump of LMS6002D registers 0x00 to 0x7f.
+func DecodeLmsRegisters(registers [lmsRegisterCount]uint8) LmsRegisters {
+	txVga2 := int(registers[0x45] >> 3)
+
+	if txVga2 > 25 {
+		txVga2 = 25
+	}
+
+	return LmsRegisters{
+		TxEnabled:      registers[0x05]&0x08 != 0,
+		RxEnabled:      registers[0x05]&0x04 != 0,
+		TxPll:          decodeLmsPll(registers[0x10:0x20]),
+		RxPll:          decodeLmsPll(registers[0x20:0x30]),
+		TxLpfEnabled:   registers[0x34]&0x02 != 0,
+		TxLpfBandwidth: lmsBandwidths[registers[0x34]>>2&0xf],
+		RxLpfEnabled:   registers[0x54]&0x02 != 0,
+		RxLpfBandwidth: lmsBandwidths[registers[0x54]>>2&0xf],
+		TxVga1Gain:     int(registers[0x41]&0x1f) - 35,
+		TxVga2Gain:     txVga2,
+		LnaGain:        lmsLnaGains[registers[0x75]>>6],
+		RxVga1Gain:     rxVga1Gain(registers[0x76] & 0x7f),
+		RxVga2Gain:     int(registers[0x65]&0x1f) * 3,
+	}
+}
+
+func readLmsRegisters(device Device) (LmsRegisters, error) {
+	var registers [lmsRegisterCount]uint8
+
+	for address := range registers {
+		val, err := device.LmsRead(uint8(address))
+
+		if err != nil {
+			return LmsRegisters{}, err
+		}
+
+		registers[address] = val
+	}
+
+	return DecodeLmsRegisters(registers), nil
+}
+
+// ReadLmsRegisters dumps the LMS6002D over SPI and decodes it. Only the
+// bladeRF 1 has an LMS6002D.
+func (bladeRF *BladeRF) ReadLmsRegisters() (LmsRegisters, error) {
+	return readLmsRegisters(bladeRF)
+}
+
+func (simulator *Simulator) ReadLmsRegisters() (LmsRegisters, error) {
+	return readLmsRegisters(simulator)
+}
@@ -0,0 +1,42 @@
+package bladerf
+
+import (
+	"errors"
+	exception "github.com/erayarslan/go-bladerf/error"
+	"testing"
+)
+
+func TestDecodeLmsRegisters(t *testing.T) {
+	var registers [0x80]uint8
+
+	registers[0x05] = 0x08
+	registers[0x10], registers[0x11], registers[0x15] = 0x2f, 0xa8, 0x25<<2
+	registers[0x1a] = 0x40
+	registers[0x34] = 0x0a<<2 | 0x02
+	registers[0x41] = 0x15
+	registers[0x45] = 0x19 << 3
+	registers[0x65] = 0x0a
+	registers[0x75] = 0xc0
+	registers[0x76] = 0x78
+
+	lms := DecodeLmsRegisters(registers)
+
+	if lms.TxEnabled && !lms.RxEnabled && lms.TxPll.Frequency(LmsReferenceClock) == 915000000 && !lms.TxPll.Locked() && lms.RxPll.Locked() {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %+v", lms.TxPll)
+	}
+
+	if lms.TxLpfEnabled && lms.TxLpfBandwidth == 3840000 && lms.RxLpfBandwidth == 28000000 && lms.TxVga1Gain == -14 &&
+		lms.TxVga2Gain == 25 && lms.RxVga2Gain == 30 && lms.LnaGain == 6 && lms.RxVga1Gain == 30 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %+v", lms)
+	}
+
+	if _, err := NewSimulator().ReadLmsRegisters(); errors.Is(err, exception.Unsupported) {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err)
+	}
+}
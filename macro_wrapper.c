@@ -9,6 +9,8 @@ uint32_t MetaFlagRxNow = BLADERF_META_FLAG_RX_NOW;
 uint32_t MetaFlagRxHwUnderflow = BLADERF_META_FLAG_RX_HW_UNDERFLOW;
 uint32_t MetaFlagRxHwMiniexp1 = BLADERF_META_FLAG_RX_HW_MINIEXP1;
 uint32_t MetaFlagRxHwMiniexp2 = BLADERF_META_FLAG_RX_HW_MINIEXP2;
+uint32_t MetaStatusOverrun = BLADERF_META_STATUS_OVERRUN;
+uint32_t MetaStatusUnderrun = BLADERF_META_STATUS_UNDERRUN;
 uint8_t TriggerRegArm = BLADERF_TRIGGER_REG_ARM;
 uint8_t TriggerRegFire = BLADERF_TRIGGER_REG_FIRE;
 uint8_t TriggerRegMaster = BLADERF_TRIGGER_REG_MASTER;
@@ -9,6 +9,8 @@ extern uint32_t MetaFlagRxNow;
 extern uint32_t MetaFlagRxHwUnderflow;
 extern uint32_t MetaFlagRxHwMiniexp1;
 extern uint32_t MetaFlagRxHwMiniexp2;
+extern uint32_t MetaStatusOverrun;
+extern uint32_t MetaStatusUnderrun;
 extern uint8_t TriggerRegArm;
 extern uint8_t TriggerRegFire;
 extern uint8_t TriggerRegMaster;
@@ -2,7 +2,10 @@ package bladerf
 
 // #include "macro_wrapper.h"
 import "C"
-import "unsafe"
+import (
+	"strconv"
+	"unsafe"
+)
 
 var ReTuneNow = Timestamp(C.ReTuneNow)
 var MetaFlagTxBurstStart = uint32(C.MetaFlagTxBurstStart)
@@ -13,6 +16,8 @@ var MetaFlagRxNow = uint32(C.MetaFlagRxNow)
 var MetaFlagRxHwUnderflow = uint32(C.MetaFlagRxHwUnderflow)
 var MetaFlagRxHwMiniexp1 = uint32(C.MetaFlagRxHwMiniexp1)
 var MetaFlagRxHwMiniexp2 = uint32(C.MetaFlagRxHwMiniexp2)
+var MetaStatusOverrun = uint32(C.MetaStatusOverrun)
+var MetaStatusUnderrun = uint32(C.MetaStatusUnderrun)
 var TriggerRegArm = C.TriggerRegArm
 var TriggerRegFire = C.TriggerRegFire
 var TriggerRegMaster = C.TriggerRegMaster
@@ -31,3 +36,11 @@ func ChannelTx(ch int) Channel {
 func ChannelIsTx(ch int) bool {
 	return C.ChannelIsTx(C.int(ch)) == 1
 }
+
+func (channel Channel) String() string {
+	if ChannelIsTx(int(channel)) {
+		return "TX" + strconv.Itoa(int(channel)>>1)
+	}
+
+	return "RX" + strconv.Itoa(int(channel)>>1)
+}
@@ -0,0 +1,40 @@
+package bladerf
+
+import (
+	"context"
+	"time"
+)
+
+const pllLockPollInterval = 10 * time.Millisecond
+
+func waitPllLock(ctx context.Context, device Device) error {
+	ticker := time.NewTicker(pllLockPollInterval)
+	defer ticker.Stop()
+
+	for {
+		locked, err := device.GetPllLockState()
+
+		if err != nil {
+			return err
+		} else if locked {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// WaitPLLLock polls the ADF4002 until it reports lock to the external
+// reference, or returns the context error once ctx is done. Enable the PLL
+// with SetPllEnable and set the reference with SetPllRefClk first.
+func (bladeRF *BladeRF) WaitPLLLock(ctx context.Context) error {
+	return waitPllLock(ctx, bladeRF)
+}
+
+func (simulator *Simulator) WaitPLLLock(ctx context.Context) error {
+	return waitPllLock(ctx, simulator)
+}
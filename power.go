@@ -0,0 +1,144 @@
+package bladerf
+
+import (
+	"context"
+	exception "github.com/erayarslan/go-bladerf/error"
+	"sync"
+	"time"
+)
+
+// PowerReading is one sample of the bladeRF 2.0 power monitor, in volts,
+// amperes and watts.
+type PowerReading struct {
+	Time         time.Time
+	Source       PowerSource
+	BusVoltage   float64
+	ShuntVoltage float64
+	Current      float64
+	Power        float64
+}
+
+// PowerAlert is emitted when the board falls back to USB VBUS power.
+// Previous is PsUnknown if the board was already on VBUS at the first
+// reading.
+type PowerAlert struct {
+	Reading  PowerReading
+	Previous PowerSource
+}
+
+func readPower(device Device) (PowerReading, error) {
+	reading := PowerReading{Time: time.Now()}
+	var err error
+
+	if reading.Source, err = device.GetPowerSource(); err != nil {
+		return reading, err
+	}
+
+	for register, field := range map[PmicRegister]*float64{
+		PmicVoltageBus:   &reading.BusVoltage,
+		PmicVoltageShunt: &reading.ShuntVoltage,
+		PmicCurrent:      &reading.Current,
+		PmicPower:        &reading.Power,
+	} {
+		if *field, err = device.GetPmicRegister(register); err != nil {
+			return reading, err
+		}
+	}
+
+	return reading, nil
+}
+
+func (bladeRF *BladeRF) ReadPower() (PowerReading, error) {
+	return readPower(bladeRF)
+}
+
+func (simulator *Simulator) ReadPower() (PowerReading, error) {
+	return readPower(simulator)
+}
+
+// PowerMonitor samples the power monitor of a device at a fixed interval
+// and reports every switch to USB VBUS power on Alerts.
+type PowerMonitor struct {
+	device   Device
+	interval time.Duration
+	alerts   chan PowerAlert
+	mu       sync.Mutex
+	latest   PowerReading
+}
+
+func NewPowerMonitor(device Device, interval time.Duration) (*PowerMonitor, error) {
+	if interval <= 0 {
+		return nil, codeError(exception.Inval, "power_monitor", "interval", interval)
+	}
+
+	return &PowerMonitor{device: device, interval: interval, alerts: make(chan PowerAlert, 1)}, nil
+}
+
+// Alerts holds at most one pending alert. Sampling never waits for it to be
+// read: an alert still unread when the next one is raised is replaced, so a
+// caller that only polls Latest loses nothing but stale alerts. The channel
+// is closed when Run returns.
+func (monitor *PowerMonitor) Alerts() <-chan PowerAlert {
+	return monitor.alerts
+}
+
+// Latest returns the most recent successful reading.
+func (monitor *PowerMonitor) Latest() PowerReading {
+	monitor.mu.Lock()
+	defer monitor.mu.Unlock()
+
+	return monitor.latest
+}
+
+// alert replaces any unread alert with the new one. Run is the only sender,
+// so once the slot has been emptied the send cannot block.
+func (monitor *PowerMonitor) alert(alert PowerAlert) {
+	select {
+	case monitor.alerts <- alert:
+		return
+	default:
+	}
+
+	select {
+	case <-monitor.alerts:
+	default:
+	}
+
+	monitor.alerts <- alert
+}
+
+// Run samples until ctx is done or a reading fails, and returns the error
+// that stopped it. An alert is only sent when the source changes, so a
+// board that stays on VBUS is reported once.
+func (monitor *PowerMonitor) Run(ctx context.Context) error {
+	defer close(monitor.alerts)
+
+	ticker := time.NewTicker(monitor.interval)
+	defer ticker.Stop()
+
+	previous := PsUnknown
+
+	for {
+		reading, err := readPower(monitor.device)
+
+		if err != nil {
+			return err
+		}
+
+		monitor.mu.Lock()
+		monitor.latest = reading
+		monitor.mu.Unlock()
+
+		if reading.Source == PsUsbVbus && previous != PsUsbVbus {
+			monitor.alert(PowerAlert{Reading: reading, Previous: previous})
+		}
+
+		previous = reading.Source
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package sigmf
+
+import (
+	"bufio"
+	"encoding/binary"
+	"github.com/erayarslan/go-bladerf"
+	"github.com/erayarslan/go-bladerf/iq"
+	"io"
+	"os"
+)
+
+// Reader reads the samples of a SigMF recording as interleaved SC16Q11,
+// widening ci8 recordings on the fly.
+type Reader struct {
+	Metadata Metadata
+	file     *os.File
+	reader   *bufio.Reader
+	buffer   []byte
+}
+
+func Open(path string) (*Reader, error) {
+	metadata, err := ReadMetadata(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(basePath(path) + DataExtension)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reader{Metadata: metadata, file: file, reader: bufio.NewReader(file)}, nil
+}
+
+func (reader *Reader) sampleSize() int {
+	if reader.Metadata.Global.Datatype == DatatypeCi8 {
+		return 2
+	}
+
+	return 4
+}
+
+// Read fills buf with up to len(buf)/2 samples and returns how many were
+// read. It returns io.EOF once the recording is exhausted.
+func (reader *Reader) Read(buf []int16) (int, error) {
+	sampleSize := reader.sampleSize()
+	size := len(buf) / 2 * sampleSize
+
+	if cap(reader.buffer) < size {
+		reader.buffer = make([]byte, size)
+	}
+
+	buffer := reader.buffer[:size]
+	n, err := io.ReadFull(reader.reader, buffer)
+
+	if err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+
+	count := n / sampleSize
+	buffer = buffer[:count*sampleSize]
+
+	if sampleSize == 2 {
+		iq.Sc8Q7ToSc16Q11(buf, bladerf.Int8View(buffer))
+	} else {
+		for i := range buf[:count*2] {
+			buf[i] = int16(binary.LittleEndian.Uint16(buffer[2*i:]))
+		}
+	}
+
+	if count == 0 && err == nil {
+		err = io.EOF
+	}
+
+	return count, err
+}
+
+func (reader *Reader) Close() error {
+	return reader.file.Close()
+}
+
+// Replay tunes channel to the first capture of the recording and transmits
+// it with SyncTX in bursts of bufferSize samples. The device must already
+// have been configured with SyncConfig for an SC16Q11 format and the TX
+// module enabled.
+func (reader *Reader) Replay(device bladerf.Device, channel bladerf.Channel, bufferSize int, timeout uint) error {
+	if len(reader.Metadata.Captures) > 0 {
+		err := device.SetFrequency(channel, uint64(reader.Metadata.Captures[0].Frequency))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if reader.Metadata.Global.SampleRate > 0 {
+		_, err := device.SetSampleRate(channel, uint(reader.Metadata.Global.SampleRate))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	current := make([]int16, bufferSize*2)
+	next := make([]int16, bufferSize*2)
+	count, err := reader.Read(current)
+	flags := bladerf.MetaFlagTxBurstStart | bladerf.MetaFlagTxNow
+
+	for err == nil {
+		var nextCount int
+		nextCount, err = reader.Read(next)
+
+		if err == io.EOF {
+			flags |= bladerf.MetaFlagTxBurstEnd
+		} else if err != nil {
+			return err
+		}
+
+		_, txErr := device.SyncTX(current[:count*2], bladerf.NewMetadata(0, flags), timeout)
+
+		if txErr != nil {
+			return txErr
+		}
+
+		current, next = next, current
+		count = nextCount
+		flags = 0
+	}
+
+	if err == io.EOF {
+		return nil
+	}
+
+	return err
+}
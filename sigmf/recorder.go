@@ -0,0 +1,215 @@
+package sigmf
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"github.com/erayarslan/go-bladerf"
+	"os"
+	"time"
+)
+
+// Recorder writes received blocks to a SigMF recording. The RF settings of
+// the channel are read from the device once, when the recording is created.
+type Recorder struct {
+	path      string
+	file      *os.File
+	writer    *bufio.Writer
+	buffer    []byte
+	metadata  Metadata
+	frequency float64
+	gain      int
+	samples   uint64
+	next      bladerf.Timestamp
+}
+
+func Create(path string, device bladerf.Device, channel bladerf.Channel, format bladerf.Format) (*Recorder, error) {
+	sampleRate, err := device.GetSampleRate(channel)
+
+	if err != nil {
+		return nil, err
+	}
+
+	frequency, err := device.GetFrequency(channel)
+
+	if err != nil {
+		return nil, err
+	}
+
+	gain, err := device.GetGain(channel)
+
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := device.GetSerialStruct()
+
+	if err != nil {
+		return nil, err
+	}
+
+	fpgaVersion, err := device.GetFpgaVersion()
+
+	if err != nil {
+		return nil, err
+	}
+
+	firmwareVersion, err := device.GetFirmwareVersion()
+
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Create(basePath(path) + DataExtension)
+
+	if err != nil {
+		return nil, err
+	}
+
+	board := device.GetBoardName()
+	recorder := &Recorder{
+		path:      path,
+		file:      file,
+		writer:    bufio.NewWriter(file),
+		frequency: float64(frequency),
+		gain:      gain,
+		metadata: Metadata{
+			Global: Global{
+				Datatype:        Datatype(format),
+				SampleRate:      float64(sampleRate),
+				Version:         Version,
+				Recorder:        "go-bladerf",
+				Hw:              fmt.Sprintf("%s serial %s, FPGA %s, firmware %s", board, serial.Serial, fpgaVersion.Describe, firmwareVersion.Describe),
+				Board:           board,
+				Serial:          serial.Serial,
+				FpgaVersion:     fpgaVersion.Describe,
+				FirmwareVersion: firmwareVersion.Describe,
+				Channel:         channel.String(),
+			},
+			Captures:    []Capture{},
+			Annotations: []Annotation{},
+		},
+	}
+
+	return recorder, nil
+}
+
+func (recorder *Recorder) Metadata() Metadata {
+	return recorder.metadata
+}
+
+func (recorder *Recorder) capture(count int, metadata bladerf.Metadata, overrun bool) {
+	timestamp := metadata.Timestamp
+
+	if timestamp == 0 {
+		timestamp = recorder.next
+	}
+
+	recorder.metadata.Captures = append(recorder.metadata.Captures, Capture{
+		SampleStart: recorder.samples,
+		Frequency:   recorder.frequency,
+		Datetime:    time.Now().UTC().Format(time.RFC3339Nano),
+		Timestamp:   uint64(timestamp),
+		Gain:        recorder.gain,
+		Overrun:     overrun,
+	})
+
+	recorder.samples += uint64(count)
+	recorder.next = timestamp + bladerf.Timestamp(count)
+}
+
+// Write appends interleaved SC16Q11 samples received with metadata.
+func (recorder *Recorder) Write(samples []int16, metadata bladerf.Metadata) error {
+	return recorder.write(samples, metadata, false)
+}
+
+func (recorder *Recorder) write(samples []int16, metadata bladerf.Metadata, overrun bool) error {
+	if recorder.metadata.Global.Datatype != DatatypeCi16 {
+		return fmt.Errorf("sigmf: %s recording cannot store 16-bit samples", recorder.metadata.Global.Datatype)
+	}
+
+	if cap(recorder.buffer) < len(samples)*2 {
+		recorder.buffer = make([]byte, len(samples)*2)
+	}
+
+	buffer := recorder.buffer[:len(samples)*2]
+
+	for i, sample := range samples {
+		binary.LittleEndian.PutUint16(buffer[2*i:], uint16(sample))
+	}
+
+	if _, err := recorder.writer.Write(buffer); err != nil {
+		return err
+	}
+
+	recorder.capture(len(samples)/2, metadata, overrun)
+	return nil
+}
+
+// Write8 appends interleaved SC8Q7 samples received with metadata.
+func (recorder *Recorder) Write8(samples []int8, metadata bladerf.Metadata) error {
+	if recorder.metadata.Global.Datatype != DatatypeCi8 {
+		return fmt.Errorf("sigmf: %s recording cannot store 8-bit samples", recorder.metadata.Global.Datatype)
+	}
+
+	if cap(recorder.buffer) < len(samples) {
+		recorder.buffer = make([]byte, len(samples))
+	}
+
+	buffer := recorder.buffer[:len(samples)]
+
+	for i, sample := range samples {
+		buffer[i] = byte(sample)
+	}
+
+	if _, err := recorder.writer.Write(buffer); err != nil {
+		return err
+	}
+
+	recorder.capture(len(samples)/2, metadata, false)
+	return nil
+}
+
+func (recorder *Recorder) WriteBlock(block bladerf.SampleBlock) error {
+	if block.Err != nil {
+		return block.Err
+	}
+
+	return recorder.write(block.Samples, bladerf.Metadata{Timestamp: block.Timestamp, Status: block.Status}, block.Overrun)
+}
+
+// Record writes every block from a StartRX channel until it is closed and
+// returns the first stream or write error. The caller should cancel the
+// stream context once Record returns early. The recording is left open.
+func (recorder *Recorder) Record(blocks <-chan bladerf.SampleBlock) error {
+	for block := range blocks {
+		if err := recorder.WriteBlock(block); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (recorder *Recorder) Annotate(sampleStart uint64, sampleCount uint64, comment string) {
+	recorder.metadata.Annotations = append(recorder.metadata.Annotations, Annotation{
+		SampleStart: sampleStart,
+		SampleCount: sampleCount,
+		Comment:     comment,
+	})
+}
+
+// Close flushes the samples and writes the .sigmf-meta file.
+func (recorder *Recorder) Close() error {
+	err := recorder.writer.Flush()
+
+	if closeErr := recorder.file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return writeMetadata(recorder.path, recorder.metadata)
+}
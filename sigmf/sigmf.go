@@ -0,0 +1,111 @@
+// Package sigmf records bladeRF captures as SigMF recordings (a
+// .sigmf-data file of raw samples next to a .sigmf-meta JSON description)
+// and plays them back.
+package sigmf
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/erayarslan/go-bladerf"
+	"os"
+	"strings"
+)
+
+const (
+	Version       = "1.0.0"
+	DataExtension = ".sigmf-data"
+	MetaExtension = ".sigmf-meta"
+
+	DatatypeCi16 = "ci16_le"
+	DatatypeCi8  = "ci8"
+)
+
+type Global struct {
+	Datatype        string  `json:"core:datatype"`
+	SampleRate      float64 `json:"core:sample_rate"`
+	Version         string  `json:"core:version"`
+	Hw              string  `json:"core:hw,omitempty"`
+	Recorder        string  `json:"core:recorder,omitempty"`
+	Description     string  `json:"core:description,omitempty"`
+	Board           string  `json:"bladerf:board,omitempty"`
+	Serial          string  `json:"bladerf:serial,omitempty"`
+	FpgaVersion     string  `json:"bladerf:fpga_version,omitempty"`
+	FirmwareVersion string  `json:"bladerf:firmware_version,omitempty"`
+	Channel         string  `json:"bladerf:channel,omitempty"`
+}
+
+// Capture starts a segment of the recording. Timestamp is the device sample
+// counter reported in the bladerf.Metadata of the block that opened it.
+type Capture struct {
+	SampleStart uint64  `json:"core:sample_start"`
+	Frequency   float64 `json:"core:frequency"`
+	Datetime    string  `json:"core:datetime,omitempty"`
+	Timestamp   uint64  `json:"bladerf:timestamp"`
+	Gain        int     `json:"bladerf:gain"`
+	Overrun     bool    `json:"bladerf:overrun,omitempty"`
+}
+
+type Annotation struct {
+	SampleStart uint64 `json:"core:sample_start"`
+	SampleCount uint64 `json:"core:sample_count"`
+	Comment     string `json:"core:comment,omitempty"`
+}
+
+type Metadata struct {
+	Global      Global       `json:"global"`
+	Captures    []Capture    `json:"captures"`
+	Annotations []Annotation `json:"annotations"`
+}
+
+// Datatype returns the SigMF datatype that stores samples of format.
+func Datatype(format bladerf.Format) string {
+	if format.SampleSize() == 2 {
+		return DatatypeCi8
+	}
+
+	return DatatypeCi16
+}
+
+func basePath(path string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(path, DataExtension), MetaExtension)
+}
+
+func ReadMetadata(path string) (Metadata, error) {
+	var metadata Metadata
+
+	file, err := os.Open(basePath(path) + MetaExtension)
+
+	if err != nil {
+		return metadata, err
+	}
+
+	defer file.Close()
+
+	if err = json.NewDecoder(file).Decode(&metadata); err != nil {
+		return metadata, fmt.Errorf("sigmf: %s: %v", file.Name(), err)
+	}
+
+	if metadata.Global.Datatype != DatatypeCi16 && metadata.Global.Datatype != DatatypeCi8 {
+		return metadata, fmt.Errorf("sigmf: unsupported datatype %q", metadata.Global.Datatype)
+	}
+
+	return metadata, nil
+}
+
+func writeMetadata(path string, metadata Metadata) error {
+	file, err := os.Create(basePath(path) + MetaExtension)
+
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+
+	if err = encoder.Encode(metadata); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,93 @@
+package sigmf
+
+import (
+	"context"
+	"github.com/erayarslan/go-bladerf"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sigmf")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	rf := bladerf.NewSimulator()
+	defer rf.Close()
+
+	rx := bladerf.ChannelRx(0)
+	_ = rf.SetFrequency(rx, 915000000)
+	_ = rf.EnableModule(rx)
+
+	path := filepath.Join(dir, "capture")
+	recorder, err := Create(path, rf, rx, bladerf.FormatSc16Q11)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	blocks, _ := rf.StartRX(ctx, bladerf.StreamConfig{
+		Layout:           bladerf.RxX1,
+		Format:           bladerf.FormatSc16Q11,
+		NumBuffers:       16,
+		SamplesPerBuffer: 1024,
+		NumTransfers:     8,
+	})
+
+	first := <-blocks
+	second := <-blocks
+	cancel()
+
+	for range blocks {
+	}
+
+	_ = recorder.WriteBlock(first)
+	_ = recorder.WriteBlock(second)
+
+	if err = recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	metadata, err := ReadMetadata(path + MetaExtension)
+
+	if err == nil && len(metadata.Captures) == 2 && metadata.Captures[0].Frequency == 915000000 &&
+		metadata.Captures[1].SampleStart == 1024 && metadata.Captures[1].Timestamp == uint64(second.Timestamp) &&
+		metadata.Global.Datatype == DatatypeCi16 && metadata.Global.FpgaVersion == "0.15.0-sim" {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", metadata)
+	}
+
+	reader, err := Open(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer reader.Close()
+
+	tx := bladerf.ChannelTx(0)
+	_ = rf.SetLoopback(bladerf.LoopbackFirmware)
+	_ = rf.SyncConfig(bladerf.TxX1, bladerf.FormatSc16Q11Meta, 16, 1024, 8, 3500)
+	_ = rf.SyncConfig(bladerf.RxX1, bladerf.FormatSc16Q11, 16, 1024, 8, 3500)
+	_ = rf.EnableModule(tx)
+
+	err = reader.Replay(rf, tx, 1024, 3500)
+	frequency, _ := rf.GetFrequency(tx)
+
+	data := make([]int16, 4096)
+	_, _, _ = rf.SyncRXInto(data, bladerf.Metadata{}, 3500)
+
+	if err == nil && frequency == 915000000 && data[0] == first.Samples[0] && data[2048] == second.Samples[0] {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err)
+	}
+}
@@ -0,0 +1,2086 @@
+package bladerf
+
+// #include <libbladeRF.h>
+import "C"
+import (
+	"context"
+	"fmt"
+	exception "github.com/erayarslan/go-bladerf/error"
+	"github.com/erayarslan/go-bladerf/iq"
+	"math"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	simulatorFlashSize     = 4194304
+	simulatorOtpSize       = 256
+	simulatorRficRegisters = 0x400
+	simulatorRssi          = -42
+	simulatorPllLockTime   = 20 * time.Millisecond
+	simulatorTxRingSize    = 1 << 20
+	simulatorToneStep      = 2 * math.Pi / 16
+)
+
+var simulatorFrequencyRange = map[Direction]Range{
+	Rx: {Min: 70000000, Max: 6000000000, Step: 1, Scale: 1},
+	Tx: {Min: 47000000, Max: 6000000000, Step: 1, Scale: 1},
+}
+
+var simulatorGainRange = map[Direction]Range{
+	Rx: {Min: -15, Max: 60, Step: 1, Scale: 1},
+	Tx: {Min: -24, Max: 66, Step: 1, Scale: 1},
+}
+
+var simulatorGainStage = map[Direction]string{
+	Rx: "full",
+	Tx: "dsa",
+}
+
+var simulatorRfPorts = map[Direction][]string{
+	Rx: {"A_BALANCED", "B_BALANCED", "C_BALANCED"},
+	Tx: {"TXA", "TXB"},
+}
+
+var simulatorSampleRateRange = Range{Min: 520834, Max: 61440000, Step: 2, Scale: 1}
+var simulatorBandwidthRange = Range{Min: 200000, Max: 56000000, Step: 1, Scale: 1}
+var simulatorPllRefClkRange = Range{Min: 5000000, Max: 300000000, Step: 1, Scale: 1}
+
+var simulatorPmic = map[PmicRegister]float64{
+	PmicConfiguration: 0x399f,
+	PmicVoltageShunt:  0.0125,
+	PmicVoltageBus:    5.05,
+	PmicCurrent:       0.625,
+	PmicPower:         3.15625,
+	PmicCalibration:   0x1000,
+}
+
+var simulatorLoopbackModes = []LoopbackModes{
+	{Name: "none", Mode: LoopbackDisabled},
+	{Name: "firmware", Mode: LoopbackFirmware},
+	{Name: "rf_bist", Mode: LoopbackRficBist},
+}
+
+var simulatorGainModes = []GainModes{
+	{Name: "automatic", Mode: GainModeDefault},
+	{Name: "manual", Mode: GainModeManual},
+	{Name: "fast", Mode: GainModeFastAttackAgc},
+	{Name: "slow", Mode: GainModeSlowAttackAgc},
+	{Name: "hybrid", Mode: GainModeHybridAgc},
+}
+
+type simulatorChannel struct {
+	direction   Direction
+	frequency   uint64
+	sampleRate  RationalRate
+	bandwidth   uint
+	gain        int
+	gainMode    GainMode
+	corrections map[Correction]int16
+	rfPort      string
+	enabled     bool
+	txMute      bool
+	biasTee     bool
+	phase       float64
+}
+
+type simulatorSync struct {
+	layout     ChannelLayout
+	format     Format
+	bufferSize uint
+}
+
+type simulatorRetune struct {
+	channel   Channel
+	timestamp Timestamp
+	frequency uint64
+}
+
+type simulatorTriggerKey struct {
+	channel Channel
+	signal  TriggerSignal
+}
+
+type simulatorTrigger struct {
+	armed         bool
+	fired         bool
+	fireRequested bool
+}
+
+type simulatorStream struct {
+	simulator        *Simulator
+	format           Format
+	samplesPerBuffer int
+	callback         func(data []int16) GoStream
+	callback8        func(data []int8) GoStream
+	rxCallback       rxCallback
+	buffers          [][]int16
+	buffers8         [][]int8
+	next             int
+}
+
+// Simulator is a pure Go stand-in for a bladeRF 2.0 micro. It keeps track of
+// the RF configuration against the ranges of the real board and produces a
+// synthetic tone (or the transmitted samples while in loopback) on receive.
+type Simulator struct {
+	mu             sync.Mutex
+	channels       map[Channel]*simulatorChannel
+	sync           map[Direction]*simulatorSync
+	streamTimeout  map[Direction]uint
+	triggers       map[simulatorTriggerKey]*simulatorTrigger
+	triggerRegs    map[simulatorTriggerKey]uint8
+	retunes        []simulatorRetune
+	loopback       Loopback
+	rxMux          RxMux
+	tuningMode     TuningMode
+	tamerMode      VctcxoTamerMode
+	trim           uint16
+	expansionBoard ExpansionBoard
+	fpgaSource     FpgaSource
+	fpgaAutoload   bool
+	configGpio     uint32
+	expansionGpio  uint32
+	expansionDir   uint32
+	clock          Timestamp
+	flash          []uint8
+	otp            []uint8
+	otpLocked      bool
+	txRing         []int16
+	rxFir          RficRxFir
+	txFir          RficTxFir
+	rficRegisters  map[uint16]uint8
+	clockSelect    ClockSelect
+	clockOutput    bool
+	pllEnabled     bool
+	pllRefClk      uint64
+	pllSettled     time.Time
+	powerSource    PowerSource
+	impairments    Impairments
+	table          *CorrectionTable
+	noise          *rand.Rand
+}
+
+// Impairments are the analog imperfections of the simulated front end that
+// CalibrateCorrections is meant to remove. DC and LO leakage are in SC16 Q11
+// counts, the TX gain mismatch is a fraction and the phase error in degrees.
+type Impairments struct {
+	RxDcI   float64
+	RxDcQ   float64
+	TxLoI   float64
+	TxLoQ   float64
+	TxGain  float64
+	TxPhase float64
+}
+
+func NewSimulator() *Simulator {
+	simulator := &Simulator{
+		channels:      make(map[Channel]*simulatorChannel),
+		sync:          make(map[Direction]*simulatorSync),
+		streamTimeout: map[Direction]uint{Rx: 1000, Tx: 1000},
+		triggers:      make(map[simulatorTriggerKey]*simulatorTrigger),
+		triggerRegs:   make(map[simulatorTriggerKey]uint8),
+		loopback:      LoopbackDisabled,
+		rxMux:         RxMuxBaseband,
+		tuningMode:    TuningModeHost,
+		tamerMode:     VctcxoTamerModeDisabled,
+		trim:          0x1ffc,
+		fpgaSource:    FpgaSourceFlash,
+		fpgaAutoload:  true,
+		flash:         make([]uint8, simulatorFlashSize),
+		otp:           make([]uint8, simulatorOtpSize),
+		rxFir:         RficRxFirDec1,
+		txFir:         RficTxFirInt1,
+		rficRegisters: make(map[uint16]uint8),
+		clockSelect:   ClockSelectOnboard,
+		pllRefClk:     10000000,
+		powerSource:   PsDc,
+		noise:         rand.New(rand.NewSource(1)),
+	}
+
+	for i := range simulator.flash {
+		simulator.flash[i] = 0xff
+	}
+
+	for i := range simulator.otp {
+		simulator.otp[i] = 0xff
+	}
+
+	for i := 0; i < 2; i++ {
+		simulator.channels[ChannelRx(i)] = newSimulatorChannel(Rx)
+		simulator.channels[ChannelTx(i)] = newSimulatorChannel(Tx)
+	}
+
+	return simulator
+}
+
+func newSimulatorChannel(direction Direction) *simulatorChannel {
+	return &simulatorChannel{
+		direction:   direction,
+		frequency:   2400000000,
+		sampleRate:  RationalRate{Integer: 30720000, Num: 0, Den: 1},
+		bandwidth:   18000000,
+		gain:        int(simulatorGainRange[direction].Min+simulatorGainRange[direction].Max) / 2,
+		gainMode:    GainModeDefault,
+		corrections: make(map[Correction]int16),
+		rfPort:      simulatorRfPorts[direction][0],
+	}
+}
+
+func inRange(value int64, _range Range) bool {
+	return value >= _range.Min && value <= _range.Max
+}
+
+func clampToRange(value int64, _range Range) int64 {
+	if value < _range.Min {
+		return _range.Min
+	}
+
+	if value > _range.Max {
+		return _range.Max
+	}
+
+	return value
+}
+
+func (simulator *Simulator) channel(channel Channel, operation string) (*simulatorChannel, error) {
+	ch, ok := simulator.channels[channel]
+
+	if !ok {
+		return nil, codeError(exception.Inval, operation, "ch", channel)
+	}
+
+	return ch, nil
+}
+
+func (simulator *Simulator) advance(samples uint) {
+	simulator.clock += Timestamp(samples)
+
+	pending := simulator.retunes[:0]
+
+	for _, retune := range simulator.retunes {
+		if retune.timestamp <= simulator.clock {
+			simulator.channels[retune.channel].frequency = retune.frequency
+		} else {
+			pending = append(pending, retune)
+		}
+	}
+
+	simulator.retunes = pending
+}
+
+func (simulator *Simulator) popTx() (int16, int16) {
+	if len(simulator.txRing) < 2 {
+		return 0, 0
+	}
+
+	i, q := simulator.txRing[0], simulator.txRing[1]
+	simulator.txRing = simulator.txRing[2:]
+	return i, q
+}
+
+// sample returns the next RX sample of ch. Firmware loopback hands back the
+// transmitted samples untouched, while the analog loopback modes pass them
+// through the TX corrections and impairments first.
+func (simulator *Simulator) sample(ch *simulatorChannel) (int16, int16) {
+	if simulator.loopback == LoopbackFirmware {
+		return simulator.popTx()
+	}
+
+	var i, q float64
+
+	if simulator.loopback != LoopbackDisabled {
+		txI, txQ := simulator.popTx()
+		i, q = simulator.txChain(float64(txI), float64(txQ))
+	} else {
+		amplitude := 512 * math.Pow(10, float64(ch.gain-30)/20)
+		ch.phase = math.Mod(ch.phase+simulatorToneStep, 2*math.Pi)
+		i, q = amplitude*math.Cos(ch.phase), amplitude*math.Sin(ch.phase)
+	}
+
+	i += float64(simulator.noise.Intn(5)-2) + simulator.impairments.RxDcI + float64(ch.corrections[CorrectionDcoffI])
+	q += float64(simulator.noise.Intn(5)-2) + simulator.impairments.RxDcQ + float64(ch.corrections[CorrectionDcoffQ])
+
+	return simulatorClip(i), simulatorClip(q)
+}
+
+// txChain applies the TX0 gain and phase corrections, then the modulator
+// impairments, then the LO leakage and DC corrections. Gain corrections of
+// +/-4096 scale I by +/-1.0 and phase corrections of +/-4096 skew Q by
+// +/-10 degrees, as on the bladeRF 1.
+func (simulator *Simulator) txChain(i float64, q float64) (float64, float64) {
+	tx := simulator.channels[ChannelTx(0)]
+	impairments := simulator.impairments
+
+	gain := float64(tx.corrections[CorrectionGain]) / 4096
+	phase := float64(tx.corrections[CorrectionPhase]) / 4096 * 10 * math.Pi / 180
+
+	i, q = i*(1+gain), q+i*math.Sin(phase)
+	i, q = i*(1+impairments.TxGain), q+i*math.Sin(impairments.TxPhase*math.Pi/180)
+
+	i += impairments.TxLoI + float64(tx.corrections[CorrectionDcoffI])
+	q += impairments.TxLoQ + float64(tx.corrections[CorrectionDcoffQ])
+
+	return i, q
+}
+
+func simulatorClip(value float64) int16 {
+	if value > 2047 {
+		return 2047
+	}
+
+	if value < -2048 {
+		return -2048
+	}
+
+	return int16(value)
+}
+
+func (simulator *Simulator) receive(layout ChannelLayout, samples []int16) {
+	for i := 0; i < len(samples)/2; i++ {
+		channel := ChannelRx(0)
+
+		if layout == RxX2 {
+			channel = ChannelRx(i % 2)
+		}
+
+		samples[2*i], samples[2*i+1] = simulator.sample(simulator.channels[channel])
+	}
+}
+
+func (simulator *Simulator) transmit(samples []int16) {
+	if simulator.channels[ChannelTx(0)].txMute {
+		samples = make([]int16, len(samples))
+	}
+
+	simulator.txRing = append(simulator.txRing, samples...)
+
+	if overflow := len(simulator.txRing) - simulatorTxRingSize; overflow > 0 {
+		simulator.txRing = simulator.txRing[overflow:]
+	}
+}
+
+func (simulator *Simulator) LoadFpga(imagePath string) error {
+	if _, err := os.Stat(imagePath); err != nil {
+		return codeError(exception.NoFile, "load_fpga", "path", imagePath)
+	}
+
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	simulator.fpgaSource = FpgaSourceHost
+	return nil
+}
+
+func (simulator *Simulator) FlashFirmware(imagePath string) error {
+	if _, err := os.Stat(imagePath); err != nil {
+		return codeError(exception.NoFile, "flash_firmware", "path", imagePath)
+	}
+
+	return nil
+}
+
+func (simulator *Simulator) FlashFpga(imagePath string) error {
+	if _, err := os.Stat(imagePath); err != nil {
+		return codeError(exception.NoFile, "flash_fpga", "path", imagePath)
+	}
+
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	simulator.fpgaAutoload = true
+	return nil
+}
+
+func (simulator *Simulator) EraseStoredFpga() error {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	simulator.fpgaAutoload = false
+	return nil
+}
+
+// DeviceReset disables every module and reloads the FPGA from flash, or
+// leaves it unconfigured when autoload has been erased.
+func (simulator *Simulator) DeviceReset() error {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	for _, ch := range simulator.channels {
+		ch.enabled = false
+	}
+
+	simulator.fpgaSource = FpgaSourceUnknown
+
+	if simulator.fpgaAutoload {
+		simulator.fpgaSource = FpgaSourceFlash
+	}
+
+	return nil
+}
+
+func (simulator *Simulator) JumpToBootloader() error {
+	return simulator.DeviceReset()
+}
+
+func (simulator *Simulator) GetFpgaSize() (FpgaSize, error) {
+	return FpgaSizeA4, nil
+}
+
+func (simulator *Simulator) GetQuickTune(channel Channel) (QuickTune, error) {
+	if _, err := simulator.channel(channel, "get_quick_tune"); err != nil {
+		return QuickTune{}, err
+	}
+
+	var quickTune C.struct_bladerf_quick_tune
+	return QuickTune{ref: &quickTune}, nil
+}
+
+func (simulator *Simulator) CancelScheduledReTunes(channel Channel) error {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	pending := simulator.retunes[:0]
+
+	for _, retune := range simulator.retunes {
+		if retune.channel != channel {
+			pending = append(pending, retune)
+		}
+	}
+
+	simulator.retunes = pending
+	return nil
+}
+
+func (simulator *Simulator) GetFpgaSource() (FpgaSource, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	return simulator.fpgaSource, nil
+}
+
+func (simulator *Simulator) GetFpgaBytes() (uint32, error) {
+	return 2632660, nil
+}
+
+func (simulator *Simulator) GetFpgaFlashSize() (uint32, bool, error) {
+	return simulatorFlashSize, false, nil
+}
+
+func (simulator *Simulator) GetFirmwareVersion() (Version, error) {
+	return Version{Major: 2, Minor: 4, Patch: 0, Describe: "2.4.0-sim"}, nil
+}
+
+func (simulator *Simulator) IsFpgaConfigured() (bool, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	return simulator.fpgaSource != FpgaSourceUnknown, nil
+}
+
+func (simulator *Simulator) GetDeviceSpeed() DeviceSpeed {
+	return SpeedSuper
+}
+
+func (simulator *Simulator) GetFpgaVersion() (Version, error) {
+	return Version{Major: 0, Minor: 15, Patch: 0, Describe: "0.15.0-sim"}, nil
+}
+
+func (simulator *Simulator) GetDeviceInfo() (DeviceInfo, error) {
+	serial, _ := simulator.GetSerial()
+
+	return DeviceInfo{
+		Backend:      BackendDummy,
+		Serial:       serial,
+		Manufacturer: "Nuand",
+		Product:      "bladeRF 2.0 (simulated)",
+	}, nil
+}
+
+func (simulator *Simulator) Close() {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	for _, ch := range simulator.channels {
+		ch.enabled = false
+	}
+}
+
+func (simulator *Simulator) SetLoopback(loopback Loopback) error {
+	if !simulator.IsLoopbackModeSupported(loopback) {
+		return codeError(exception.Unsupported, "set_loopback", "lb", loopback)
+	}
+
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	simulator.loopback = loopback
+	simulator.txRing = nil
+	return nil
+}
+
+func (simulator *Simulator) IsLoopbackModeSupported(loopback Loopback) bool {
+	for _, mode := range simulatorLoopbackModes {
+		if mode.Mode == loopback {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (simulator *Simulator) GetLoopback() (Loopback, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	return simulator.loopback, nil
+}
+
+func (simulator *Simulator) ScheduleReTune(
+	channel Channel,
+	timestamp Timestamp,
+	frequency uint64,
+	quickTune QuickTune,
+) error {
+	if timestamp == ReTuneNow {
+		return simulator.SetFrequency(channel, frequency)
+	}
+
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	ch, err := simulator.channel(channel, "schedule_retune")
+
+	if err != nil {
+		return err
+	}
+
+	if !inRange(int64(frequency), simulatorFrequencyRange[ch.direction]) {
+		return codeError(exception.Range, "schedule_retune", "ch", channel, "ts", timestamp, "freq", hertz(frequency))
+	}
+
+	if len(simulator.retunes) >= RetuneQueueDepth {
+		return codeError(exception.QueueFull, "schedule_retune", "ch", channel, "ts", timestamp, "freq", hertz(frequency))
+	}
+
+	simulator.retunes = append(simulator.retunes, simulatorRetune{
+		channel:   channel,
+		timestamp: timestamp,
+		frequency: frequency,
+	})
+
+	return nil
+}
+
+func (simulator *Simulator) SelectBand(channel Channel, frequency uint64) error {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	ch, err := simulator.channel(channel, "select_band")
+
+	if err != nil {
+		return err
+	}
+
+	if !inRange(int64(frequency), simulatorFrequencyRange[ch.direction]) {
+		return codeError(exception.Range, "select_band", "ch", channel, "freq", hertz(frequency))
+	}
+
+	return nil
+}
+
+func (simulator *Simulator) SetFrequency(channel Channel, frequency uint64) error {
+	table, err := simulator.setFrequency(channel, frequency)
+
+	if err != nil || table == nil {
+		return err
+	}
+
+	return table.Apply(simulator, channel, frequency)
+}
+
+func (simulator *Simulator) setFrequency(channel Channel, frequency uint64) (*CorrectionTable, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	ch, err := simulator.channel(channel, "set_frequency")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !inRange(int64(frequency), simulatorFrequencyRange[ch.direction]) {
+		return nil, codeError(exception.Range, "set_frequency", "ch", channel, "freq", hertz(frequency))
+	}
+
+	ch.frequency = frequency
+	return simulator.table, nil
+}
+
+func (simulator *Simulator) GetFrequency(channel Channel) (uint64, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	ch, err := simulator.channel(channel, "get_frequency")
+
+	if err != nil {
+		return 0, err
+	}
+
+	return ch.frequency, nil
+}
+
+func (simulator *Simulator) SetSampleRate(channel Channel, sampleRate uint) (uint, error) {
+	actual, err := simulator.SetRationalSampleRate(channel, RationalRate{Integer: uint64(sampleRate), Den: 1})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return uint(actual.Integer), nil
+}
+
+func (simulator *Simulator) SetRxMux(mux RxMux) error {
+	if mux == RxMuxInvalid {
+		return codeError(exception.Inval, "set_rx_mux", "mux", mux)
+	}
+
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	simulator.rxMux = mux
+	return nil
+}
+
+func (simulator *Simulator) GetRxMux() (RxMux, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	return simulator.rxMux, nil
+}
+
+func (simulator *Simulator) SetRationalSampleRate(channel Channel, rationalRate RationalRate) (RationalRate, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	ch, err := simulator.channel(channel, "set_rational_sample_rate")
+
+	if err != nil {
+		return RationalRate{}, err
+	}
+
+	if rationalRate.Den == 0 {
+		return RationalRate{}, codeError(exception.Inval, "set_rational_sample_rate", "ch", channel, "rate", hertz(rationalRate.Integer))
+	}
+
+	actual := RationalRate{
+		Integer: rationalRate.Integer + rationalRate.Num/rationalRate.Den,
+		Num:     rationalRate.Num % rationalRate.Den,
+		Den:     rationalRate.Den,
+	}
+
+	if !inRange(int64(actual.Integer), simulatorSampleRateRange) {
+		return RationalRate{}, codeError(exception.Range, "set_rational_sample_rate", "ch", channel, "rate", hertz(rationalRate.Integer))
+	}
+
+	ch.sampleRate = actual
+	return actual, nil
+}
+
+func (simulator *Simulator) GetSampleRate(channel Channel) (uint, error) {
+	rate, err := simulator.GetRationalSampleRate(channel)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return uint(rate.Integer), nil
+}
+
+func (simulator *Simulator) GetRationalSampleRate(channel Channel) (RationalRate, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	ch, err := simulator.channel(channel, "get_rational_sample_rate")
+
+	if err != nil {
+		return RationalRate{}, err
+	}
+
+	return ch.sampleRate, nil
+}
+
+func (simulator *Simulator) GetSampleRateRange(channel Channel) (Range, error) {
+	if _, err := simulator.channel(channel, "get_sample_rate_range"); err != nil {
+		return Range{}, err
+	}
+
+	return simulatorSampleRateRange, nil
+}
+
+func (simulator *Simulator) GetFrequencyRange(channel Channel) (Range, error) {
+	ch, err := simulator.channel(channel, "get_frequency_range")
+
+	if err != nil {
+		return Range{}, err
+	}
+
+	return simulatorFrequencyRange[ch.direction], nil
+}
+
+func (simulator *Simulator) SetBandwidth(channel Channel, bandwidth uint) (uint, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	ch, err := simulator.channel(channel, "set_bandwidth")
+
+	if err != nil {
+		return 0, err
+	}
+
+	ch.bandwidth = uint(clampToRange(int64(bandwidth), simulatorBandwidthRange))
+	return ch.bandwidth, nil
+}
+
+func (simulator *Simulator) GetBandwidth(channel Channel) (uint, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	ch, err := simulator.channel(channel, "get_bandwidth")
+
+	if err != nil {
+		return 0, err
+	}
+
+	return ch.bandwidth, nil
+}
+
+func (simulator *Simulator) GetBandwidthRange(channel Channel) (Range, error) {
+	if _, err := simulator.channel(channel, "get_bandwidth_range"); err != nil {
+		return Range{}, err
+	}
+
+	return simulatorBandwidthRange, nil
+}
+
+func (simulator *Simulator) SetGain(channel Channel, gain int) error {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	ch, err := simulator.channel(channel, "set_gain")
+
+	if err != nil {
+		return err
+	}
+
+	ch.gain = int(clampToRange(int64(gain), simulatorGainRange[ch.direction]))
+	return nil
+}
+
+func (simulator *Simulator) GetGain(channel Channel) (int, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	ch, err := simulator.channel(channel, "get_gain")
+
+	if err != nil {
+		return 0, err
+	}
+
+	return ch.gain, nil
+}
+
+func (simulator *Simulator) GetGainStage(channel Channel, stage string) (int, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	ch, err := simulator.channel(channel, "get_gain_stage")
+
+	if err != nil {
+		return 0, err
+	}
+
+	if stage != simulatorGainStage[ch.direction] {
+		return 0, codeError(exception.Inval, "get_gain_stage", "ch", channel, "stage", stage)
+	}
+
+	return ch.gain, nil
+}
+
+func (simulator *Simulator) GetGainMode(channel Channel) (GainMode, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	ch, err := simulator.channel(channel, "get_gain_mode")
+
+	if err != nil {
+		return 0, err
+	}
+
+	return ch.gainMode, nil
+}
+
+func (simulator *Simulator) SetGainStage(channel Channel, stage string, gain int) error {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	ch, err := simulator.channel(channel, "set_gain_stage")
+
+	if err != nil {
+		return err
+	}
+
+	if stage != simulatorGainStage[ch.direction] {
+		return codeError(exception.Inval, "set_gain_stage", "ch", channel, "stage", stage, "gain", gain)
+	}
+
+	ch.gain = int(clampToRange(int64(gain), simulatorGainRange[ch.direction]))
+	return nil
+}
+
+func (simulator *Simulator) GetGainStageRange(channel Channel, stage string) (Range, error) {
+	ch, err := simulator.channel(channel, "get_gain_stage_range")
+
+	if err != nil {
+		return Range{}, err
+	}
+
+	if stage != simulatorGainStage[ch.direction] {
+		return Range{}, codeError(exception.Inval, "get_gain_stage_range", "ch", channel, "stage", stage)
+	}
+
+	return simulatorGainRange[ch.direction], nil
+}
+
+func (simulator *Simulator) GetGainRange(channel Channel) (Range, error) {
+	ch, err := simulator.channel(channel, "get_gain_range")
+
+	if err != nil {
+		return Range{}, err
+	}
+
+	return simulatorGainRange[ch.direction], nil
+}
+
+func (simulator *Simulator) GetNumberOfGainStages(channel Channel) (int, error) {
+	if _, err := simulator.channel(channel, "get_gain_stages"); err != nil {
+		return 0, err
+	}
+
+	return 1, nil
+}
+
+func (simulator *Simulator) SetCorrection(channel Channel, correction Correction, correctionValue int16) error {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	ch, err := simulator.channel(channel, "set_correction")
+
+	if err != nil {
+		return err
+	}
+
+	limit := int16(4096)
+
+	if correction == CorrectionDcoffI || correction == CorrectionDcoffQ {
+		limit = 2047
+	}
+
+	if correctionValue > limit || correctionValue < -limit {
+		return codeError(exception.Range, "set_correction", "ch", channel, "corr", correction, "value", correctionValue)
+	}
+
+	ch.corrections[correction] = correctionValue
+	return nil
+}
+
+func (simulator *Simulator) GetCorrection(channel Channel, correction Correction) (int16, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	ch, err := simulator.channel(channel, "get_correction")
+
+	if err != nil {
+		return 0, err
+	}
+
+	return ch.corrections[correction], nil
+}
+
+func (simulator *Simulator) SetCorrectionTable(table *CorrectionTable) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	simulator.table = table
+}
+
+func (simulator *Simulator) GetCorrectionTable() *CorrectionTable {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	return simulator.table
+}
+
+// SetImpairments sets the front end imperfections added to received
+// samples. The zero value models an ideal radio.
+func (simulator *Simulator) SetImpairments(impairments Impairments) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	simulator.impairments = impairments
+}
+
+func (simulator *Simulator) GetBoardName() string {
+	return "bladerf2"
+}
+
+func (simulator *Simulator) GetSerial() (string, error) {
+	return "0123456789abcdef0123456789abcdef", nil
+}
+
+func (simulator *Simulator) GetSerialStruct() (Serial, error) {
+	serial, err := simulator.GetSerial()
+	return Serial{Serial: serial}, err
+}
+
+func (simulator *Simulator) GetGainStages(channel Channel) ([]string, error) {
+	ch, err := simulator.channel(channel, "get_gain_stages")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{simulatorGainStage[ch.direction]}, nil
+}
+
+func (simulator *Simulator) GetGainModes(channel Channel) ([]GainModes, error) {
+	ch, err := simulator.channel(channel, "get_gain_modes")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if ch.direction == Tx {
+		return make([]GainModes, 0), nil
+	}
+
+	return append([]GainModes(nil), simulatorGainModes...), nil
+}
+
+func (simulator *Simulator) GetLoopbackModes() ([]LoopbackModes, error) {
+	return append([]LoopbackModes(nil), simulatorLoopbackModes...), nil
+}
+
+func (simulator *Simulator) SetGainMode(channel Channel, mode GainMode) error {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	ch, err := simulator.channel(channel, "set_gain_mode")
+
+	if err != nil {
+		return err
+	}
+
+	if ch.direction == Tx {
+		return codeError(exception.Unsupported, "set_gain_mode", "ch", channel, "mode", mode)
+	}
+
+	ch.gainMode = mode
+	return nil
+}
+
+func (simulator *Simulator) EnableModule(channel Channel) error {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	ch, err := simulator.channel(channel, "enable_module")
+
+	if err != nil {
+		return err
+	}
+
+	ch.enabled = true
+	return nil
+}
+
+func (simulator *Simulator) IsModuleEnabled(channel Channel) bool {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	ch, ok := simulator.channels[channel]
+	return ok && ch.enabled
+}
+
+func (simulator *Simulator) DisableModule(channel Channel) error {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	ch, err := simulator.channel(channel, "enable_module")
+
+	if err != nil {
+		return err
+	}
+
+	ch.enabled = false
+	return nil
+}
+
+func (simulator *Simulator) TriggerInit(channel Channel, signal TriggerSignal) (Trigger, error) {
+	if _, err := simulator.channel(channel, "trigger_init"); err != nil {
+		return Trigger{}, err
+	}
+
+	if signal == TriggerSignalInvalid {
+		return Trigger{}, codeError(exception.Inval, "trigger_init", "ch", channel, "signal", signal)
+	}
+
+	trigger := C.struct_bladerf_trigger{
+		channel: C.bladerf_channel(channel),
+		role:    C.bladerf_trigger_role(TriggerRoleDisabled),
+		signal:  C.bladerf_trigger_signal(signal),
+	}
+
+	return Trigger{ref: &trigger}, nil
+}
+
+func (simulator *Simulator) trigger(trigger Trigger) (*simulatorTrigger, error) {
+	if trigger.ref == nil {
+		return nil, exception.New(int(exception.Inval))
+	}
+
+	key := simulatorTriggerKey{channel: Channel(trigger.ref.channel), signal: TriggerSignal(trigger.ref.signal)}
+	state, ok := simulator.triggers[key]
+
+	if !ok {
+		state = &simulatorTrigger{}
+		simulator.triggers[key] = state
+	}
+
+	return state, nil
+}
+
+func (simulator *Simulator) TriggerArm(trigger Trigger, arm bool, resV1 uint64, resV2 uint64) error {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	state, err := simulator.trigger(trigger)
+
+	if err != nil {
+		return err
+	}
+
+	state.armed = arm
+
+	if !arm {
+		state.fired = false
+		state.fireRequested = false
+	}
+
+	return nil
+}
+
+func (simulator *Simulator) TriggerFire(trigger Trigger) error {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	state, err := simulator.trigger(trigger)
+
+	if err != nil {
+		return err
+	}
+
+	if TriggerRole(trigger.ref.role) != TriggerRoleMaster {
+		return codeError(exception.Inval, "trigger_fire")
+	}
+
+	state.fireRequested = true
+	state.fired = state.armed
+	return nil
+}
+
+func (simulator *Simulator) TriggerState(trigger Trigger) (bool, bool, bool, uint64, uint64, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	state, err := simulator.trigger(trigger)
+
+	if err != nil {
+		return false, false, false, 0, 0, err
+	}
+
+	return state.armed, state.fired, state.fireRequested, 0, 0, nil
+}
+
+func (simulator *Simulator) SyncTX(input []int16, metadata Metadata, timeout uint) (Metadata, error) {
+	return simulator.SyncTXFrom(input, metadata, timeout)
+}
+
+func (simulator *Simulator) SyncTXFrom(input []int16, metadata Metadata, timeout uint) (Metadata, error) {
+	return simulator.syncTX(input, 4, metadata)
+}
+
+func (simulator *Simulator) SyncTX8(input []int8, metadata Metadata, timeout uint) (Metadata, error) {
+	return simulator.SyncTX8From(input, metadata, timeout)
+}
+
+func (simulator *Simulator) SyncTX8From(input []int8, metadata Metadata, timeout uint) (Metadata, error) {
+	samples := make([]int16, len(input))
+	iq.Sc8Q7ToSc16Q11(samples, input)
+	return simulator.syncTX(samples, 2, metadata)
+}
+
+func (simulator *Simulator) syncTX(input []int16, sampleSize int, metadata Metadata) (Metadata, error) {
+	if metadata.ref == nil {
+		var ref C.struct_bladerf_metadata
+		metadata.ref = &ref
+	}
+
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	config, ok := simulator.sync[Tx]
+
+	if !ok || config.format.SampleSize() != sampleSize {
+		return metadata, codeError(exception.Inval, "sync_tx", "samples", len(input)/2)
+	}
+
+	if !simulator.channels[ChannelTx(0)].enabled {
+		return metadata, codeError(exception.Timeout, "sync_tx", "samples", len(input)/2)
+	}
+
+	if config.format.HasMetadata() && metadata.Flags&MetaFlagTxNow == 0 &&
+		metadata.Flags&MetaFlagTxBurstStart != 0 {
+		if metadata.Timestamp < simulator.clock {
+			return metadata, codeError(exception.TimePast, "sync_tx", "samples", len(input)/2, "ts", metadata.Timestamp)
+		}
+
+		simulator.advance(uint(metadata.Timestamp - simulator.clock))
+	}
+
+	simulator.transmit(input)
+	simulator.advance(uint(len(input) / 2))
+
+	return LoadMetadata(metadata.ref), nil
+}
+
+func (simulator *Simulator) SyncRX(bufferSize uintptr, metadata Metadata, timeout uint) ([]int16, Metadata, error) {
+	results := make([]int16, bufferSize*2)
+	count, metadata, err := simulator.SyncRXInto(results, metadata, timeout)
+
+	if err != nil {
+		return nil, metadata, err
+	}
+
+	return results[:count*2], metadata, nil
+}
+
+func (simulator *Simulator) SyncRXInto(buf []int16, metadata Metadata, timeout uint) (int, Metadata, error) {
+	return simulator.syncRX(buf, 4, metadata)
+}
+
+func (simulator *Simulator) SyncRX8(bufferSize uintptr, metadata Metadata, timeout uint) ([]int8, Metadata, error) {
+	results := make([]int8, bufferSize*2)
+	count, metadata, err := simulator.SyncRX8Into(results, metadata, timeout)
+
+	if err != nil {
+		return nil, metadata, err
+	}
+
+	return results[:count*2], metadata, nil
+}
+
+func (simulator *Simulator) SyncRX8Into(buf []int8, metadata Metadata, timeout uint) (int, Metadata, error) {
+	samples := make([]int16, len(buf))
+	count, metadata, err := simulator.syncRX(samples, 2, metadata)
+	iq.Sc16Q11ToSc8Q7(buf, samples)
+	return count, metadata, err
+}
+
+func (simulator *Simulator) syncRX(buf []int16, sampleSize int, metadata Metadata) (int, Metadata, error) {
+	if metadata.ref == nil {
+		var ref C.struct_bladerf_metadata
+		metadata.ref = &ref
+	}
+
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	config, ok := simulator.sync[Rx]
+
+	if !ok || config.format.SampleSize() != sampleSize {
+		return 0, metadata, codeError(exception.Inval, "sync_rx", "samples", len(buf)/2)
+	}
+
+	if !simulator.channels[ChannelRx(0)].enabled {
+		return 0, metadata, codeError(exception.Timeout, "sync_rx", "samples", len(buf)/2)
+	}
+
+	if config.format.HasMetadata() && metadata.Flags&MetaFlagRxNow == 0 &&
+		metadata.Timestamp > simulator.clock {
+		simulator.advance(uint(metadata.Timestamp - simulator.clock))
+	}
+
+	numberOfSample := len(buf) / 2
+	metadata.ref.timestamp = C.uint64_t(simulator.clock)
+	metadata.ref.actual_count = C.uint(numberOfSample)
+	metadata.ref.status = 0
+
+	simulator.receive(config.layout, buf)
+	simulator.advance(uint(numberOfSample))
+
+	return numberOfSample, LoadMetadata(metadata.ref), nil
+}
+
+func (simulator *Simulator) InitStream(
+	format Format,
+	numBuffers int,
+	samplesPerBuffer int,
+	numTransfers int,
+	callback func(data []int16) GoStream,
+) (Stream, error) {
+	if format != FormatSc16Q11 && format != FormatSc16Q11Meta {
+		return Stream{}, codeError(exception.Inval, "init_stream", "buffers", numBuffers, "samples", samplesPerBuffer, "transfers", numTransfers)
+	}
+
+	if samplesPerBuffer <= 0 || samplesPerBuffer%1024 != 0 || numTransfers >= numBuffers {
+		return Stream{}, codeError(exception.Inval, "init_stream", "buffers", numBuffers, "samples", samplesPerBuffer, "transfers", numTransfers)
+	}
+
+	stream := &simulatorStream{
+		simulator:        simulator,
+		format:           format,
+		samplesPerBuffer: samplesPerBuffer,
+		callback:         callback,
+		buffers:          make([][]int16, numBuffers),
+	}
+
+	for i := range stream.buffers {
+		stream.buffers[i] = make([]int16, samplesPerBuffer*2)
+	}
+
+	return Stream{simulator: stream}, nil
+}
+
+func (simulator *Simulator) InitStream8(
+	format Format,
+	numBuffers int,
+	samplesPerBuffer int,
+	numTransfers int,
+	callback func(data []int8) GoStream,
+) (Stream, error) {
+	if format != FormatSc8Q7 && format != FormatSc8Q7Meta {
+		return Stream{}, codeError(exception.Inval, "init_stream", "buffers", numBuffers, "samples", samplesPerBuffer, "transfers", numTransfers)
+	}
+
+	if samplesPerBuffer <= 0 || samplesPerBuffer%1024 != 0 || numTransfers >= numBuffers {
+		return Stream{}, codeError(exception.Inval, "init_stream", "buffers", numBuffers, "samples", samplesPerBuffer, "transfers", numTransfers)
+	}
+
+	stream := &simulatorStream{
+		simulator:        simulator,
+		format:           format,
+		samplesPerBuffer: samplesPerBuffer,
+		callback8:        callback,
+		buffers8:         make([][]int8, numBuffers),
+	}
+
+	for i := range stream.buffers8 {
+		stream.buffers8[i] = make([]int8, samplesPerBuffer*2)
+	}
+
+	return Stream{simulator: stream}, nil
+}
+
+func (stream *simulatorStream) start(layout ChannelLayout) error {
+	simulator := stream.simulator
+	direction := layoutDirection(layout)
+	channel := ChannelRx(0)
+
+	if direction == Tx {
+		channel = ChannelTx(0)
+	}
+
+	frame := make([]int16, stream.samplesPerBuffer*2)
+	results := make([]int16, stream.samplesPerBuffer*2)
+	results8 := make([]int8, stream.samplesPerBuffer*2)
+
+	for {
+		var status GoStream
+
+		simulator.mu.Lock()
+
+		if !simulator.channels[channel].enabled {
+			simulator.mu.Unlock()
+			return codeError(exception.Timeout, "stream", "layout", layout)
+		}
+
+		timestamp := simulator.clock
+
+		if direction == Rx {
+			simulator.receive(layout, frame)
+			simulator.advance(uint(stream.samplesPerBuffer))
+			simulator.mu.Unlock()
+
+			if stream.rxCallback != nil {
+				status = stream.rxCallback(frame, Metadata{Timestamp: timestamp})
+			} else if stream.callback8 != nil {
+				iq.Sc16Q11ToSc8Q7(results8, frame)
+				status = stream.callback8(results8)
+			} else {
+				copy(results, frame)
+				status = stream.callback(results)
+			}
+		} else {
+			data := frame
+			simulator.mu.Unlock()
+
+			if stream.callback8 != nil {
+				status = stream.callback8(stream.buffers8[stream.next])
+				iq.Sc8Q7ToSc16Q11(data, stream.buffers8[stream.next])
+			} else {
+				data = stream.buffers[stream.next]
+				status = stream.callback(data)
+			}
+
+			if status == GoStreamNext {
+				simulator.mu.Lock()
+				simulator.transmit(data)
+				simulator.advance(uint(stream.samplesPerBuffer))
+				stream.next = (stream.next + 1) % stream.numBuffers()
+				simulator.mu.Unlock()
+			}
+		}
+
+		if status == GoStreamShutdown {
+			return nil
+		}
+	}
+}
+
+func (stream *simulatorStream) numBuffers() int {
+	if stream.callback8 != nil {
+		return len(stream.buffers8)
+	}
+
+	return len(stream.buffers)
+}
+
+func (stream *simulatorStream) submit8(buffer []int8) error {
+	samples := make([]int16, len(buffer))
+	iq.Sc8Q7ToSc16Q11(samples, buffer)
+	return stream.submit(samples)
+}
+
+func (stream *simulatorStream) submit(buffer []int16) error {
+	simulator := stream.simulator
+
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	if !simulator.channels[ChannelTx(0)].enabled {
+		return codeError(exception.Timeout, "submit_stream_buffer")
+	}
+
+	simulator.transmit(buffer)
+	simulator.advance(uint(len(buffer) / 2))
+	return nil
+}
+
+func (simulator *Simulator) StartRX(ctx context.Context, config StreamConfig) (<-chan SampleBlock, error) {
+	stream, err := simulator.InitStream(
+		config.Format,
+		config.NumBuffers,
+		config.SamplesPerBuffer,
+		config.NumTransfers,
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return receiveBlocks(ctx, config, func(callback rxCallback) error {
+		stream.simulator.rxCallback = callback
+		return stream.Start(config.Layout)
+	}, func() {
+		disableLayout(simulator, config.Layout)
+	}, stream.DeInit), nil
+}
+
+func (simulator *Simulator) GetStreamTimeout(direction Direction) (uint, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	return simulator.streamTimeout[direction], nil
+}
+
+func (simulator *Simulator) SetStreamTimeout(direction Direction, timeout uint) error {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	simulator.streamTimeout[direction] = timeout
+	return nil
+}
+
+func (simulator *Simulator) SyncConfig(
+	layout ChannelLayout,
+	format Format,
+	numBuffers uint,
+	bufferSize uint,
+	numTransfers uint,
+	timeout uint,
+) error {
+	if format != FormatSc16Q11 && format != FormatSc16Q11Meta && format != FormatSc8Q7 && format != FormatSc8Q7Meta {
+		return codeError(exception.Inval, "sync_config", "layout", layout, "format", format, "buffers", numBuffers, "size", bufferSize, "transfers", numTransfers)
+	}
+
+	if bufferSize == 0 || bufferSize%1024 != 0 || numTransfers >= numBuffers {
+		return codeError(exception.Inval, "sync_config", "layout", layout, "format", format, "buffers", numBuffers, "size", bufferSize, "transfers", numTransfers)
+	}
+
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	direction := layoutDirection(layout)
+	simulator.sync[direction] = &simulatorSync{layout: layout, format: format, bufferSize: bufferSize}
+	simulator.streamTimeout[direction] = timeout
+	return nil
+}
+
+func (simulator *Simulator) syncSettings(direction Direction) (Format, uint) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	config, ok := simulator.sync[direction]
+
+	if !ok {
+		return FormatSc16Q11, 0
+	}
+
+	return config.format, config.bufferSize
+}
+
+func (simulator *Simulator) AttachExpansionBoard(expansionBoard ExpansionBoard) error {
+	if expansionBoard != ExpansionBoardNone {
+		return codeError(exception.Unsupported, "expansion_attach", "xb", expansionBoard)
+	}
+
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	simulator.expansionBoard = expansionBoard
+	return nil
+}
+
+func (simulator *Simulator) GetAttachedExpansionBoard() (ExpansionBoard, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	return simulator.expansionBoard, nil
+}
+
+func (simulator *Simulator) XB200SetFilterbank(channel Channel, filter XB200Filter) error {
+	return codeError(exception.Unsupported, "xb200_set_filterbank", "ch", channel, "filter", filter)
+}
+
+func (simulator *Simulator) XB200GetFilterbank(channel Channel) (XB200Filter, error) {
+	return 0, codeError(exception.Unsupported, "xb200_get_filterbank", "ch", channel)
+}
+
+func (simulator *Simulator) XB200SetPath(channel Channel, path XB200Path) error {
+	return codeError(exception.Unsupported, "xb200_set_path", "ch", channel, "path", path)
+}
+
+func (simulator *Simulator) XB200GetPath(channel Channel) (XB200Path, error) {
+	return 0, codeError(exception.Unsupported, "xb200_get_path", "ch", channel)
+}
+
+func (simulator *Simulator) XB300SetTrx(trx XB300Trx) error {
+	return codeError(exception.Unsupported, "xb300_set_trx", "trx", trx)
+}
+
+func (simulator *Simulator) XB300GetTrx() (XB300Trx, error) {
+	return XB300TrxInvalid, codeError(exception.Unsupported, "xb300_get_trx")
+}
+
+func (simulator *Simulator) XB300SetAmplifierEnable(amplifier XB300Amplifier, enable bool) error {
+	return codeError(exception.Unsupported, "xb300_set_amplifier_enable", "amp", amplifier, "enable", enable)
+}
+
+func (simulator *Simulator) XB300GetAmplifierEnable(amplifier XB300Amplifier) (bool, error) {
+	return false, codeError(exception.Unsupported, "xb300_get_amplifier_enable", "amp", amplifier)
+}
+
+func (simulator *Simulator) XB300GetOutputPower() (float32, error) {
+	return 0, codeError(exception.Unsupported, "xb300_get_output_power")
+}
+
+// GetRficRssi reports the level of the simulated tone referred to the
+// antenna, which does not depend on the gain setting.
+func (simulator *Simulator) GetRficRssi(channel Channel) (int32, int32, error) {
+	if _, err := simulator.channel(channel, "get_rfic_rssi"); err != nil {
+		return 0, 0, err
+	}
+
+	return simulatorRssi, simulatorRssi, nil
+}
+
+func (simulator *Simulator) GetRficTemperature() (float32, error) {
+	return 41.5, nil
+}
+
+func (simulator *Simulator) GetRficCtrlOut() (uint8, error) {
+	return 0, nil
+}
+
+func (simulator *Simulator) GetRficRxFir() (RficRxFir, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	return simulator.rxFir, nil
+}
+
+func (simulator *Simulator) SetRficRxFir(fir RficRxFir) error {
+	if fir < RficRxFirBypass || fir > RficRxFirDec4 {
+		return codeError(exception.Inval, "set_rfic_rx_fir", "fir", fir)
+	}
+
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	simulator.rxFir = fir
+	return nil
+}
+
+func (simulator *Simulator) GetRficTxFir() (RficTxFir, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	return simulator.txFir, nil
+}
+
+func (simulator *Simulator) SetRficTxFir(fir RficTxFir) error {
+	if fir < RficTxFirBypass || fir > RficTxFirInt4 {
+		return codeError(exception.Inval, "set_rfic_tx_fir", "fir", fir)
+	}
+
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	simulator.txFir = fir
+	return nil
+}
+
+func (simulator *Simulator) GetRficRegister(address uint16) (uint8, error) {
+	if address >= simulatorRficRegisters {
+		return 0, codeError(exception.Inval, "get_rfic_register", "addr", fmt.Sprintf("0x%03x", address))
+	}
+
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	return simulator.rficRegisters[address], nil
+}
+
+func (simulator *Simulator) SetRficRegister(address uint16, val uint8) error {
+	if address >= simulatorRficRegisters {
+		return codeError(exception.Inval, "set_rfic_register", "addr", fmt.Sprintf("0x%03x", address), "val", val)
+	}
+
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	simulator.rficRegisters[address] = val
+	return nil
+}
+
+func (simulator *Simulator) SetTxMute(channel Channel, mute bool) error {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	ch, err := simulator.channel(channel, "set_tx_mute")
+
+	if err != nil {
+		return err
+	}
+
+	if ch.direction != Tx {
+		return codeError(exception.Inval, "set_tx_mute", "ch", channel, "state", mute)
+	}
+
+	ch.txMute = mute
+	return nil
+}
+
+func (simulator *Simulator) GetTxMute(channel Channel) (bool, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	ch, err := simulator.channel(channel, "get_tx_mute")
+
+	if err != nil {
+		return false, err
+	}
+
+	if ch.direction != Tx {
+		return false, codeError(exception.Inval, "get_tx_mute", "ch", channel)
+	}
+
+	return ch.txMute, nil
+}
+
+func (simulator *Simulator) SetBiasTee(channel Channel, enable bool) error {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	ch, err := simulator.channel(channel, "set_bias_tee")
+
+	if err != nil {
+		return err
+	}
+
+	ch.biasTee = enable
+	return nil
+}
+
+func (simulator *Simulator) GetBiasTee(channel Channel) (bool, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	ch, err := simulator.channel(channel, "get_bias_tee")
+
+	if err != nil {
+		return false, err
+	}
+
+	return ch.biasTee, nil
+}
+
+func (simulator *Simulator) SetClockSelect(selection ClockSelect) error {
+	if selection != ClockSelectOnboard && selection != ClockSelectExternal {
+		return codeError(exception.Inval, "set_clock_select", "sel", selection)
+	}
+
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	simulator.clockSelect = selection
+	return nil
+}
+
+func (simulator *Simulator) GetClockSelect() (ClockSelect, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	return simulator.clockSelect, nil
+}
+
+func (simulator *Simulator) SetClockOutput(enable bool) error {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	simulator.clockOutput = enable
+	return nil
+}
+
+func (simulator *Simulator) GetClockOutput() (bool, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	return simulator.clockOutput, nil
+}
+
+// SetPllEnable starts the simulated ADF4002, which reports lock
+// simulatorPllLockTime after it is enabled or its reference changes.
+func (simulator *Simulator) SetPllEnable(enable bool) error {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	simulator.pllEnabled = enable
+	simulator.pllSettled = time.Now().Add(simulatorPllLockTime)
+	return nil
+}
+
+func (simulator *Simulator) GetPllEnable() (bool, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	return simulator.pllEnabled, nil
+}
+
+func (simulator *Simulator) SetPllRefClk(frequency uint64) error {
+	if !inRange(int64(frequency), simulatorPllRefClkRange) {
+		return codeError(exception.Range, "set_pll_refclk", "freq", hertz(frequency))
+	}
+
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	simulator.pllRefClk = frequency
+	simulator.pllSettled = time.Now().Add(simulatorPllLockTime)
+	return nil
+}
+
+func (simulator *Simulator) GetPllRefClk() (uint64, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	return simulator.pllRefClk, nil
+}
+
+func (simulator *Simulator) GetPllRefClkRange() (Range, error) {
+	return simulatorPllRefClkRange, nil
+}
+
+func (simulator *Simulator) GetPllLockState() (bool, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	return simulator.pllEnabled && !time.Now().Before(simulator.pllSettled), nil
+}
+
+func (simulator *Simulator) GetPmicRegister(register PmicRegister) (float64, error) {
+	val, ok := simulatorPmic[register]
+
+	if !ok {
+		return 0, codeError(exception.Inval, "get_pmic_register", "reg", register)
+	}
+
+	return val, nil
+}
+
+func (simulator *Simulator) GetPowerSource() (PowerSource, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	return simulator.powerSource, nil
+}
+
+func (simulator *Simulator) LmsRead(address uint8) (uint8, error) {
+	return 0, codeError(exception.Unsupported, "lms_read", "addr", fmt.Sprintf("0x%02x", address))
+}
+
+func (simulator *Simulator) LmsWrite(address uint8, val uint8) error {
+	return codeError(exception.Unsupported, "lms_write", "addr", fmt.Sprintf("0x%02x", address), "val", fmt.Sprintf("0x%02x", val))
+}
+
+func (simulator *Simulator) LmsSetDcCals(dcCals LmsDcCals) error {
+	return codeError(exception.Unsupported, "lms_set_dc_cals")
+}
+
+func (simulator *Simulator) LmsGetDcCals() (LmsDcCals, error) {
+	return LmsDcCals{}, codeError(exception.Unsupported, "lms_get_dc_cals")
+}
+
+func (simulator *Simulator) Si5338Read(address uint8) (uint8, error) {
+	return 0, codeError(exception.Unsupported, "si5338_read", "addr", address)
+}
+
+func (simulator *Simulator) Si5338Write(address uint8, val uint8) error {
+	return codeError(exception.Unsupported, "si5338_write", "addr", address, "val", fmt.Sprintf("0x%02x", val))
+}
+
+func (simulator *Simulator) Si5338SetTxFreq(frequency uint) error {
+	return codeError(exception.Unsupported, "si5338_set_tx_freq", "freq", hertz(frequency))
+}
+
+func (simulator *Simulator) Si5338SetRxFreq(frequency uint) error {
+	return codeError(exception.Unsupported, "si5338_set_rx_freq", "freq", hertz(frequency))
+}
+
+func (simulator *Simulator) Si5338SetSmbFreq(frequency uint) error {
+	return codeError(exception.Unsupported, "si5338_set_smb_freq", "freq", hertz(frequency))
+}
+
+func (simulator *Simulator) SetVctcxoTamerMode(mode VctcxoTamerMode) error {
+	if mode == VctcxoTamerModeInvalid {
+		return codeError(exception.Inval, "set_vctcxo_tamer_mode", "mode", mode)
+	}
+
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	simulator.tamerMode = mode
+	return nil
+}
+
+func (simulator *Simulator) GetVctcxoTamerMode() (VctcxoTamerMode, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	return simulator.tamerMode, nil
+}
+
+// GetVctcxoTrim reports the trim stored in the calibration region, like
+// libbladeRF does, or the factory default when none has been stored.
+func (simulator *Simulator) GetVctcxoTrim() (uint16, error) {
+	if calibration, err := simulator.ReadCalibration(); err == nil {
+		return calibration.DacTrim, nil
+	}
+
+	return 0x1ffc, nil
+}
+
+func (simulator *Simulator) TrimDacRead() (uint16, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	return simulator.trim, nil
+}
+
+func (simulator *Simulator) TrimDacWrite(val uint16) error {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	simulator.trim = val
+	return nil
+}
+
+func (simulator *Simulator) SetTuningMode(mode TuningMode) error {
+	if mode == TuningModeInvalid {
+		return codeError(exception.Inval, "set_tuning_mode", "mode", mode)
+	}
+
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	simulator.tuningMode = mode
+	return nil
+}
+
+func (simulator *Simulator) GetTuningMode() (TuningMode, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	return simulator.tuningMode, nil
+}
+
+func (simulator *Simulator) GetTimestamp(direction Direction) (Timestamp, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	return simulator.clock, nil
+}
+
+func (simulator *Simulator) ReadTrigger(channel Channel, signal TriggerSignal) (uint8, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	return simulator.triggerRegs[simulatorTriggerKey{channel: channel, signal: signal}], nil
+}
+
+func (simulator *Simulator) WriteTrigger(channel Channel, signal TriggerSignal, val uint8) error {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	simulator.triggerRegs[simulatorTriggerKey{channel: channel, signal: signal}] = val
+	return nil
+}
+
+func (simulator *Simulator) ConfigGpioRead() (uint32, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	return simulator.configGpio, nil
+}
+
+func (simulator *Simulator) ConfigGpioWrite(val uint32) error {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	simulator.configGpio = val
+	return nil
+}
+
+// ExpansionGpioRead returns the level driven on output pins. Input pins
+// have nothing attached and read low.
+func (simulator *Simulator) ExpansionGpioRead() (uint32, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	return simulator.expansionGpio & simulator.expansionDir, nil
+}
+
+func (simulator *Simulator) ExpansionGpioWrite(val uint32) error {
+	return simulator.ExpansionGpioMaskedWrite(^uint32(0), val)
+}
+
+func (simulator *Simulator) ExpansionGpioMaskedWrite(mask uint32, val uint32) error {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	simulator.expansionGpio = simulator.expansionGpio&^mask | val&mask
+	return nil
+}
+
+func (simulator *Simulator) ExpansionGpioDirRead() (uint32, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	return simulator.expansionDir, nil
+}
+
+func (simulator *Simulator) ExpansionGpioDirWrite(outputs uint32) error {
+	return simulator.ExpansionGpioDirMaskedWrite(^uint32(0), outputs)
+}
+
+func (simulator *Simulator) ExpansionGpioDirMaskedWrite(mask uint32, outputs uint32) error {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	simulator.expansionDir = simulator.expansionDir&^mask | outputs&mask
+	return nil
+}
+
+func (simulator *Simulator) EraseFlash(eraseBlock uint32, count uint32) error {
+	return simulator.EraseFlashBytes(eraseBlock*FlashEraseBlockSize, count*FlashEraseBlockSize)
+}
+
+func (simulator *Simulator) EraseFlashBytes(address uint32, length uint32) error {
+	if address%FlashEraseBlockSize != 0 || length%FlashEraseBlockSize != 0 {
+		return codeError(exception.Misaligned, "erase_flash_bytes", "addr", fmt.Sprintf("0x%08x", address), "len", length)
+	}
+
+	if uint64(address)+uint64(length) > simulatorFlashSize {
+		return codeError(exception.Inval, "erase_flash_bytes", "addr", fmt.Sprintf("0x%08x", address), "len", length)
+	}
+
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	for i := address; i < address+length; i++ {
+		simulator.flash[i] = 0xff
+	}
+
+	return nil
+}
+
+func (simulator *Simulator) LockOtp() error {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	simulator.otpLocked = true
+	return nil
+}
+
+func (simulator *Simulator) ReadFlashBytes(address uint32, bytes uint32) ([]uint8, error) {
+	if address%FlashPageSize != 0 || bytes%FlashPageSize != 0 {
+		return nil, codeError(exception.Misaligned, "read_flash_bytes", "addr", fmt.Sprintf("0x%08x", address), "len", bytes)
+	}
+
+	if uint64(address)+uint64(bytes) > simulatorFlashSize {
+		return nil, codeError(exception.Inval, "read_flash_bytes", "addr", fmt.Sprintf("0x%08x", address), "len", bytes)
+	}
+
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	return append([]uint8(nil), simulator.flash[address:address+bytes]...), nil
+}
+
+func (simulator *Simulator) WriteFlashBytes(input []uint8, address uint32, bytes uint32) error {
+	if address%FlashPageSize != 0 || bytes%FlashPageSize != 0 {
+		return codeError(exception.Misaligned, "write_flash_bytes", "addr", fmt.Sprintf("0x%08x", address), "len", bytes)
+	}
+
+	if uint64(address)+uint64(bytes) > simulatorFlashSize || uint32(len(input)) < bytes {
+		return codeError(exception.Inval, "write_flash_bytes", "addr", fmt.Sprintf("0x%08x", address), "len", bytes)
+	}
+
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	for i := uint32(0); i < bytes; i++ {
+		simulator.flash[address+i] &= input[i]
+	}
+
+	return nil
+}
+
+func (simulator *Simulator) ReadOtp() ([]uint8, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	return append([]uint8(nil), simulator.otp...), nil
+}
+
+func (simulator *Simulator) WriteOtp(input []uint8) error {
+	if len(input) < simulatorOtpSize {
+		return codeError(exception.Inval, "write_otp")
+	}
+
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	if simulator.otpLocked {
+		return codeError(exception.Permission, "write_otp")
+	}
+
+	for i := range simulator.otp {
+		simulator.otp[i] &= input[i]
+	}
+
+	return nil
+}
+
+func (simulator *Simulator) ReadFlash(page uint32, count uint32) ([]uint8, error) {
+	return simulator.ReadFlashBytes(page*FlashPageSize, count*FlashPageSize)
+}
+
+func (simulator *Simulator) WriteFlash(input []uint8, page uint32, count uint32) error {
+	return simulator.WriteFlashBytes(input, page*FlashPageSize, count*FlashPageSize)
+}
+
+func (simulator *Simulator) SetRfPort(channel Channel, port string) error {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	ch, err := simulator.channel(channel, "set_rf_port")
+
+	if err != nil {
+		return err
+	}
+
+	for _, candidate := range simulatorRfPorts[ch.direction] {
+		if candidate == port {
+			ch.rfPort = port
+			return nil
+		}
+	}
+
+	return codeError(exception.Inval, "set_rf_port", "ch", channel, "port", port)
+}
+
+func (simulator *Simulator) GetRfPort(channel Channel) (string, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	ch, err := simulator.channel(channel, "get_rf_port")
+
+	if err != nil {
+		return "", err
+	}
+
+	return ch.rfPort, nil
+}
+
+func (simulator *Simulator) GetNumberOfRfPorts(channel Channel) (int, error) {
+	ch, err := simulator.channel(channel, "get_rf_ports")
+
+	if err != nil {
+		return 0, err
+	}
+
+	return len(simulatorRfPorts[ch.direction]), nil
+}
+
+func (simulator *Simulator) GetRfPorts(channel Channel) ([]string, error) {
+	ch, err := simulator.channel(channel, "get_rf_ports")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]string(nil), simulatorRfPorts[ch.direction]...), nil
+}
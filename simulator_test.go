@@ -0,0 +1,1352 @@
+package bladerf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	exception "github.com/erayarslan/go-bladerf/error"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSimulatorFrequency(t *testing.T) {
+	var rf Device = NewSimulator()
+	defer rf.Close()
+
+	err := rf.SetFrequency(Rx1Channel, 915000000)
+
+	if err != nil {
+		t.Errorf("FAILED cause got %v", err.Error())
+	}
+
+	frequency, err := rf.GetFrequency(Rx1Channel)
+
+	if err == nil && frequency == 915000000 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", frequency)
+	}
+
+	_range, _ := rf.GetFrequencyRange(Rx1Channel)
+
+	if err = rf.SetFrequency(Rx1Channel, uint64(_range.Max+1)); err != nil {
+		t.Log("PASSED")
+	} else {
+		t.Error("FAILED cause out of range frequency was accepted")
+	}
+}
+
+func TestSimulatorError(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	err := rf.SetFrequency(Rx1Channel, 6100000000)
+
+	if errors.Is(err, exception.Range) && !errors.Is(err, exception.Timeout) {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err)
+	}
+
+	if err.Error() == "set_frequency ch=RX0 freq=6.1e9: Provided parameter is out of range" {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err.Error())
+	}
+
+	var bladeRFError *exception.Error
+
+	if errors.As(err, &bladeRFError) && bladeRFError.Code == exception.Range {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", bladeRFError)
+	}
+
+	_, err = rf.GetGain(ChannelRx(5))
+
+	if errors.As(err, &bladeRFError) && bladeRFError.Code == exception.Inval && bladeRFError.Operation == "get_gain" {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err)
+	}
+}
+
+func TestSimulatorSampleRate(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	actual, err := rf.SetSampleRate(Rx1Channel, 10000000)
+
+	if err == nil && actual == 10000000 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", actual)
+	}
+
+	rate, err := rf.SetRationalSampleRate(Rx1Channel, RationalRate{Integer: 1000000, Num: 5, Den: 3})
+
+	if err == nil && rate.Integer == 1000001 && rate.Num == 2 && rate.Den == 3 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", rate)
+	}
+
+	if _, err = rf.SetSampleRate(Rx1Channel, 100); err != nil {
+		t.Log("PASSED")
+	} else {
+		t.Error("FAILED cause out of range sample rate was accepted")
+	}
+}
+
+func TestSimulatorGain(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	_range, _ := rf.GetGainRange(Rx1Channel)
+	_ = rf.SetGain(Rx1Channel, int(_range.Max)+10)
+	gain, err := rf.GetGain(Rx1Channel)
+
+	if err == nil && gain == int(_range.Max) {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", gain)
+	}
+
+	stages, _ := rf.GetGainStages(Rx1Channel)
+	_ = rf.SetGainStage(Rx1Channel, stages[0], 20)
+	gain, err = rf.GetGainStage(Rx1Channel, stages[0])
+
+	if err == nil && gain == 20 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", gain)
+	}
+}
+
+func TestSimulatorRfPort(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	ports, _ := rf.GetRfPorts(Rx1Channel)
+	err := rf.SetRfPort(Rx1Channel, ports[1])
+	port, _ := rf.GetRfPort(Rx1Channel)
+
+	if err == nil && port == ports[1] {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", port)
+	}
+
+	if err = rf.SetRfPort(Rx1Channel, "TXA"); err != nil {
+		t.Log("PASSED")
+	} else {
+		t.Error("FAILED cause unknown port was accepted")
+	}
+}
+
+func TestSimulatorSyncRX(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	_ = rf.SyncConfig(RxX1, FormatSc16Q11Meta, 16, 1024, 8, 3500)
+
+	if _, _, err := rf.SyncRX(1024, Metadata{}, 3500); err == nil {
+		t.Error("FAILED cause disabled module returned samples")
+	}
+
+	_ = rf.EnableModule(ChannelRx(0))
+
+	data, metadata, err := rf.SyncRX(1024, NewMetadata(0, MetaFlagRxNow), 3500)
+
+	if err == nil && len(data) == 2048 && metadata.ActualCount == 1024 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", len(data))
+	}
+
+	_, metadata, err = rf.SyncRX(1024, NewMetadata(0, MetaFlagRxNow), 3500)
+
+	if err == nil && metadata.Timestamp == 1024 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", metadata.Timestamp)
+	}
+}
+
+func TestSimulatorLoopback(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	_ = rf.SetLoopback(LoopbackFirmware)
+	_ = rf.SyncConfig(TxX1, FormatSc16Q11, 2, 1024, 1, 3500)
+	_ = rf.SyncConfig(RxX1, FormatSc16Q11, 2, 1024, 1, 3500)
+	_ = rf.EnableModule(ChannelTx(0))
+	_ = rf.EnableModule(ChannelRx(0))
+
+	input := make([]int16, 8)
+
+	for i := range input {
+		input[i] = int16(i * 100)
+	}
+
+	data := make([]int16, len(input))
+	_, err := rf.SyncTX(input, Metadata{}, 3500)
+	_, _, err = rf.SyncRXInto(data, Metadata{}, 3500)
+
+	if err != nil {
+		t.Errorf("FAILED cause got %v", err.Error())
+	}
+
+	for i := range input {
+		if data[i] != input[i] {
+			t.Errorf("FAILED cause got %v", data)
+			return
+		}
+	}
+
+	t.Log("PASSED")
+}
+
+func TestSimulatorScheduleReTune(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	_ = rf.SyncConfig(RxX1, FormatSc16Q11Meta, 16, 1024, 8, 3500)
+	_ = rf.EnableModule(Rx1Channel)
+
+	quickTune, _ := rf.GetQuickTune(Rx1Channel)
+	err := rf.ScheduleReTune(Rx1Channel, 2048, 1000000000, quickTune)
+	frequency, _ := rf.GetFrequency(Rx1Channel)
+
+	if err == nil && frequency != 1000000000 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", frequency)
+	}
+
+	_, _, _ = rf.SyncRX(4096, NewMetadata(0, MetaFlagRxNow), 3500)
+	frequency, _ = rf.GetFrequency(Rx1Channel)
+
+	if frequency == 1000000000 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", frequency)
+	}
+}
+
+func TestSimulatorAsyncRX(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	_ = rf.EnableModule(ChannelRx(0))
+	calls := 0
+	length := 0
+
+	rxStream, err := rf.InitStream(
+		FormatSc16Q11,
+		2,
+		1024,
+		1,
+		func(data []int16) GoStream {
+			calls++
+			length = len(data)
+
+			if calls == 3 {
+				return GoStreamShutdown
+			}
+
+			return GoStreamNext
+		})
+
+	if err != nil {
+		t.Errorf("FAILED cause got %v", err.Error())
+	}
+
+	err = rxStream.Start(RxX1)
+	rxStream.DeInit()
+
+	if err == nil && calls == 3 && length == 2048 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v %v", calls, length)
+	}
+}
+
+func TestSimulatorFlash(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	buf := make([]uint8, FlashPageSize)
+
+	for index := range buf {
+		buf[index] = 180
+	}
+
+	_ = rf.EraseFlash(4, 1)
+	_ = rf.WriteFlash(buf, 1024, 1)
+	output, err := rf.ReadFlash(1024, 1)
+
+	if err == nil && output[0] == 180 && output[FlashPageSize-1] == 180 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", output[0])
+	}
+
+	if err = rf.EraseFlashBytes(1, 1); err != nil {
+		t.Log("PASSED")
+	} else {
+		t.Error("FAILED cause misaligned erase was accepted")
+	}
+}
+
+func TestSimulatorAsyncTX(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	_ = rf.SetLoopback(LoopbackFirmware)
+	_ = rf.SyncConfig(RxX1, FormatSc16Q11, 2, 1024, 1, 3500)
+	_ = rf.EnableModule(ChannelTx(0))
+	_ = rf.EnableModule(ChannelRx(0))
+
+	calls := 0
+
+	txStream, err := rf.InitStream(
+		FormatSc16Q11,
+		4,
+		1024,
+		2,
+		func(data []int16) GoStream {
+			calls++
+
+			if calls > 1 {
+				return GoStreamShutdown
+			}
+
+			for i := range data {
+				data[i] = 100
+			}
+
+			return GoStreamNext
+		})
+
+	data := make([]int16, 2048)
+	err = txStream.Start(TxX1)
+	_, _, _ = rf.SyncRXInto(data, Metadata{}, 3500)
+
+	if err == nil && data[0] == 100 && data[2047] == 100 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", data[0])
+	}
+
+	buffers := txStream.Buffers()
+	buffers[1][0] = 200
+
+	err = txStream.SubmitBuffer(buffers[1], 3500)
+	_, _, _ = rf.SyncRXInto(data, Metadata{}, 3500)
+
+	if err == nil && len(buffers) == 4 && data[0] == 200 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", data[0])
+	}
+
+	if err = txStream.SubmitBufferNonBlocking(nil); errors.Is(err, exception.Inval) {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err)
+	}
+}
+
+func TestSimulatorSyncRXInto(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	_ = rf.SyncConfig(RxX1, FormatSc16Q11, 2, 1024, 1, 3500)
+	_ = rf.EnableModule(ChannelRx(0))
+
+	pool := NewSampleBufferPool(1024)
+	buf := pool.Get()
+	defer pool.Put(buf)
+
+	count, _, err := rf.SyncRXInto(buf, Metadata{}, 3500)
+
+	if err == nil && count == 1024 && len(buf) == 2048 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", count)
+	}
+}
+
+func TestSimulatorStartRX(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	_ = rf.EnableModule(ChannelRx(0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	blocks, err := rf.StartRX(ctx, StreamConfig{
+		Layout:           RxX1,
+		Format:           FormatSc16Q11,
+		NumBuffers:       16,
+		SamplesPerBuffer: 1024,
+		NumTransfers:     8,
+	})
+
+	if err != nil {
+		t.Errorf("FAILED cause got %v", err.Error())
+	}
+
+	first := <-blocks
+	second := <-blocks
+	cancel()
+
+	for range blocks {
+	}
+
+	if len(first.Samples) == 2048 && second.Timestamp > first.Timestamp && first.Err == nil {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v,%v", first.Timestamp, second.Timestamp)
+	}
+}
+
+func TestSimulatorStartRXDisabled(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	blocks, _ := rf.StartRX(context.Background(), StreamConfig{
+		Layout:           RxX1,
+		Format:           FormatSc16Q11,
+		NumBuffers:       16,
+		SamplesPerBuffer: 1024,
+		NumTransfers:     8,
+	})
+
+	block := <-blocks
+
+	if block.Err != nil {
+		t.Log("PASSED")
+	} else {
+		t.Error("FAILED cause disabled module streamed samples")
+	}
+}
+
+func TestReceiveBlocksStalled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+
+	blocks := receiveBlocks(ctx, StreamConfig{NumBuffers: 4}, func(callback rxCallback) error {
+		<-stopped
+		return exception.New(int(exception.Timeout))
+	}, func() {
+		close(stopped)
+	}, func() {})
+
+	cancel()
+
+	select {
+	case block, ok := <-blocks:
+		if !ok {
+			t.Log("PASSED")
+		} else {
+			t.Errorf("FAILED cause got %v", block.Err)
+		}
+	case <-time.After(time.Second):
+		t.Error("FAILED cause stalled stream ignored cancellation")
+	}
+}
+
+func TestSimulatorSc8Q7(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	_ = rf.SetLoopback(LoopbackFirmware)
+	_ = rf.SyncConfig(TxX1, FormatSc8Q7, 2, 1024, 1, 3500)
+	_ = rf.SyncConfig(RxX1, FormatSc8Q7, 2, 1024, 1, 3500)
+	_ = rf.EnableModule(ChannelTx(0))
+	_ = rf.EnableModule(ChannelRx(0))
+
+	input := []int8{127, -128, 64, -64}
+	data := make([]int8, len(input))
+	_, err := rf.SyncTX8(input, Metadata{}, 3500)
+	_, _, err = rf.SyncRX8Into(data, Metadata{}, 3500)
+
+	if err == nil && data[0] == 127 && data[1] == -128 && data[3] == -64 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", data)
+	}
+
+	if _, _, err = rf.SyncRXInto(make([]int16, 8), Metadata{}, 3500); err != nil {
+		t.Log("PASSED")
+	} else {
+		t.Error("FAILED cause 16-bit buffer accepted for SC8_Q7")
+	}
+}
+
+func TestSimulatorAsyncRX8(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	_ = rf.EnableModule(ChannelRx(0))
+	var samples []int8
+
+	rxStream, err := rf.InitStream8(
+		FormatSc8Q7,
+		2,
+		1024,
+		1,
+		func(data []int8) GoStream {
+			samples = data
+			return GoStreamShutdown
+		})
+
+	if err != nil {
+		t.Errorf("FAILED cause got %v", err.Error())
+	}
+
+	err = rxStream.Start(RxX1)
+	rxStream.DeInit()
+
+	if err == nil && len(samples) == 2048 && len(FormatSc8Q7.BytesToComplex64(make([]byte, 2048))) == 1024 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", len(samples))
+	}
+}
+
+func TestSimulatorSnapshot(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	_ = rf.SetFrequency(Rx1Channel, 915000000)
+	_, _ = rf.SetRationalSampleRate(Rx1Channel, RationalRate{Integer: 1000000, Num: 1, Den: 3})
+	_ = rf.SetGainMode(Rx1Channel, GainModeManual)
+	_ = rf.SetGainStage(Rx1Channel, "full", 40)
+	_ = rf.SetCorrection(Rx1Channel, CorrectionDcoffI, 12)
+	_ = rf.SetLoopback(LoopbackFirmware)
+	_ = rf.EnableModule(Rx1Channel)
+
+	snapshot, err := rf.Snapshot()
+
+	if err != nil || len(snapshot.Channels) != 4 {
+		t.Errorf("FAILED cause got %v", err)
+	}
+
+	_ = rf.SetFrequency(Rx1Channel, 2400000000)
+	_, _ = rf.SetSampleRate(Rx1Channel, 10000000)
+	_ = rf.SetGainStage(Rx1Channel, "full", 10)
+	_ = rf.SetCorrection(Rx1Channel, CorrectionDcoffI, 0)
+	_ = rf.SetLoopback(LoopbackDisabled)
+	_ = rf.DisableModule(Rx1Channel)
+
+	err = rf.Restore(snapshot)
+	frequency, _ := rf.GetFrequency(Rx1Channel)
+	rate, _ := rf.GetRationalSampleRate(Rx1Channel)
+	gain, _ := rf.GetGainStage(Rx1Channel, "full")
+	correction, _ := rf.GetCorrection(Rx1Channel, CorrectionDcoffI)
+	loopback, _ := rf.GetLoopback()
+
+	if err == nil && frequency == 915000000 && rate.Num == 1 && rate.Den == 3 && gain == 40 &&
+		correction == 12 && loopback == LoopbackFirmware && rf.IsModuleEnabled(Rx1Channel) {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err)
+	}
+
+	snapshot.Channels[0].RfPort = "TXA"
+
+	if err = rf.Restore(snapshot); err != nil && strings.Contains(err.Error(), "RX0.rf_port") {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err)
+	}
+}
+
+func TestSimulatorFlashFpga(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	if err := rf.FlashFpga("missing.rbf"); errors.Is(err, exception.NoFile) {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err)
+	}
+
+	_ = rf.EnableModule(Rx1Channel)
+	_ = rf.EraseStoredFpga()
+	err := rf.DeviceReset()
+	configured, _ := rf.IsFpgaConfigured()
+
+	if err == nil && !configured && !rf.IsModuleEnabled(Rx1Channel) {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v %v", err, configured)
+	}
+
+	_ = rf.FlashFpga("simulator.go")
+	_ = rf.DeviceReset()
+	source, _ := rf.GetFpgaSource()
+
+	if source == FpgaSourceFlash {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", source)
+	}
+}
+
+func TestSimulatorBackupFlash(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bladerf")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	rf := NewSimulator()
+	defer rf.Close()
+
+	path := filepath.Join(dir, "flash.img")
+	page := make([]uint8, FlashPageSize)
+	page[0], page[1] = 0xde, 0xad
+	_ = rf.WriteFlashBytes(page, 0x40000, FlashPageSize)
+
+	if err = rf.BackupFlash(path); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = rf.EraseFlash(4, 1)
+	err = rf.RestoreFlash(path)
+	output, _ := rf.ReadFlashBytes(0x40000, FlashPageSize)
+
+	if err == nil && output[0] == 0xde && output[1] == 0xad {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err)
+	}
+}
+
+func TestSimulatorCalibration(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	var dryRun strings.Builder
+	err := rf.StoreVctcxoTrim(0x1f00, &dryRun)
+	trim, _ := rf.TrimDacRead()
+
+	if _, calErr := rf.ReadCalibration(); err == nil && trim == 0x1ffc && calErr != nil &&
+		strings.Contains(dryRun.String(), "DAC=7936") {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v %v", err, dryRun.String())
+	}
+
+	err = rf.StoreVctcxoTrim(0x1f00, nil)
+	trim, _ = rf.TrimDacRead()
+	stored, _ := rf.GetVctcxoTrim()
+
+	if err == nil && trim == 0x1f00 && stored == 0x1f00 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v %v %v", err, trim, stored)
+	}
+}
+
+func TestSimulatorOtpFields(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	otp := Otp{Serial: "0123456789abcdef0123456789abcdef", BoardRevision: "2"}
+	err := rf.WriteOtpFields(otp, nil)
+	read, readErr := rf.ReadOtpFields()
+
+	if err == nil && readErr == nil && read.Serial == otp.Serial && read.BoardRevision == "2" {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v %v %+v", err, readErr, read)
+	}
+
+	otp.Serial = "fedcba9876543210fedcba9876543210"
+
+	if err = rf.WriteOtpFields(otp, nil); errors.Is(err, exception.Permission) {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err)
+	}
+
+	buf, _ := rf.ReadOtp()
+	buf[5] = 0
+
+	if _, err = ParseOtp(buf); errors.Is(err, exception.Checksum) {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err)
+	}
+}
+
+func TestXB200Select(t *testing.T) {
+	path, filter := XB200Select(146000000, XB200FilterAuto1dB)
+
+	if path == XB200PathMix && filter == XB200Filter144M {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v %v", path, filter)
+	}
+
+	path, filter = XB200Select(14000000, XB200FilterAuto1dB)
+
+	if path == XB200PathMix && filter == XB200FilterCustom {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v %v", path, filter)
+	}
+
+	_, filter = XB200Select(250000000, XB200FilterAuto3dB)
+	path, _ = XB200Select(433000000, XB200FilterAuto1dB)
+
+	if filter == XB200Filter222M && path == XB200PathBypass {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v %v", path, filter)
+	}
+
+	if err := NewSimulator().XB200SetPath(Rx1Channel, XB200PathMix); errors.Is(err, exception.Unsupported) {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err)
+	}
+}
+
+type xb200Recorder struct {
+	*Simulator
+	calls []string
+}
+
+func (recorder *xb200Recorder) XB200SetFilterbank(channel Channel, filter XB200Filter) error {
+	recorder.calls = append(recorder.calls, fmt.Sprintf("filter=%d", filter))
+	return nil
+}
+
+func (recorder *xb200Recorder) XB200SetPath(channel Channel, path XB200Path) error {
+	recorder.calls = append(recorder.calls, fmt.Sprintf("path=%d", path))
+	return nil
+}
+
+func (recorder *xb200Recorder) SetCorrection(channel Channel, correction Correction, value int16) error {
+	recorder.calls = append(recorder.calls, fmt.Sprintf("correction%d=%d", correction, value))
+	return nil
+}
+
+func TestXB200TuneFrequency(t *testing.T) {
+	recorder := &xb200Recorder{Simulator: NewSimulator()}
+	defer recorder.Close()
+
+	tune := func() error {
+		recorder.calls = append(recorder.calls, "tune")
+		return nil
+	}
+
+	err := tuneFrequency(recorder, ExpansionBoard200, nil, Rx1Channel, 146000000, tune)
+	calls := strings.Join(recorder.calls, ",")
+
+	if err == nil && calls == fmt.Sprintf("path=%d,tune", XB200PathMix) {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v %s", err, calls)
+	}
+
+	recorder.calls = nil
+	table := NewCorrectionTable()
+	table.Add(Rx1Channel, 433000000, map[Correction]int16{CorrectionDcoffI: 12})
+	err = tuneFrequency(recorder, ExpansionBoard200, table, Rx1Channel, 433000000, tune)
+	calls = strings.Join(recorder.calls, ",")
+
+	if err == nil && calls == fmt.Sprintf("path=%d,tune,correction%d=12", XB200PathBypass, CorrectionDcoffI) {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v %s", err, calls)
+	}
+
+	recorder.calls = nil
+	err = tuneFrequency(recorder, ExpansionBoardNone, nil, Rx1Channel, 146000000, tune)
+	calls = strings.Join(recorder.calls, ",")
+
+	if err == nil && calls == "tune" {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v %s", err, calls)
+	}
+}
+
+type xb300Recorder struct {
+	*Simulator
+	calls []string
+}
+
+func (recorder *xb300Recorder) XB300SetTrx(trx XB300Trx) error {
+	recorder.calls = append(recorder.calls, fmt.Sprintf("trx=%d", trx))
+	return nil
+}
+
+func (recorder *xb300Recorder) XB300SetAmplifierEnable(amplifier XB300Amplifier, enable bool) error {
+	recorder.calls = append(recorder.calls, fmt.Sprintf("amp%d=%v", amplifier, enable))
+	return nil
+}
+
+func (recorder *xb300Recorder) EnableModule(channel Channel) error {
+	recorder.calls = append(recorder.calls, "enable "+channel.String())
+	return nil
+}
+
+func (recorder *xb300Recorder) DisableModule(channel Channel) error {
+	recorder.calls = append(recorder.calls, "disable "+channel.String())
+	return nil
+}
+
+func TestXB300SwitchTo(t *testing.T) {
+	recorder := &xb300Recorder{Simulator: NewSimulator()}
+	defer recorder.Close()
+
+	err := xb300SwitchTo(recorder, Tx)
+	calls := strings.Join(recorder.calls, ",")
+
+	if err == nil && calls == "disable RX0,amp1=false,trx=0,amp0=true,enable TX0" {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v %s", err, calls)
+	}
+
+	recorder.calls = nil
+	err = xb300SwitchTo(recorder, Rx)
+	calls = strings.Join(recorder.calls, ",")
+
+	if err == nil && calls == "disable TX0,amp0=false,amp2=false,trx=1,amp1=true,enable RX0" {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v %s", err, calls)
+	}
+}
+
+func TestSimulatorExpansionGPIO(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	gpio := NewExpansionGPIO(rf)
+	relay := gpio.Pin(ExpansionPin03)
+	_ = gpio.Write(0x80000001)
+	_ = relay.SetOutput(true)
+	err := relay.Set(true)
+	high, _ := relay.Get()
+	output, _ := gpio.IsOutput(ExpansionPin03)
+
+	if err == nil && high && output && ExpansionPin32.Mask() == 0x80000000 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v %v %v", err, high, output)
+	}
+
+	_ = relay.Toggle()
+	high, _ = relay.Get()
+	_ = gpio.SetOutput(ExpansionPin01, true)
+	val, _ := gpio.Read()
+
+	if !high && val == 0x1 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v 0x%x", high, val)
+	}
+}
+
+func TestSimulatorRfic(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	_ = rf.SetLoopback(LoopbackFirmware)
+	_ = rf.SyncConfig(TxX1, FormatSc16Q11, 2, 1024, 1, 3500)
+	_ = rf.SyncConfig(RxX1, FormatSc16Q11, 2, 1024, 1, 3500)
+	_ = rf.EnableModule(ChannelTx(0))
+	_ = rf.EnableModule(ChannelRx(0))
+
+	err := rf.SetTxMute(ChannelTx(0), true)
+	muted, _ := rf.GetTxMute(ChannelTx(0))
+	data := make([]int16, 8)
+	_, _ = rf.SyncTX([]int16{100, 200, 300, 400, 500, 600, 700, 800}, Metadata{}, 3500)
+	_, _, _ = rf.SyncRXInto(data, Metadata{}, 3500)
+
+	if err == nil && muted && data[0] == 0 && data[7] == 0 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v %v", err, data)
+	}
+
+	_ = rf.SetRficRxFir(RficRxFirDec2)
+	_ = rf.SetRficRegister(0x037, 0x5a)
+	fir, _ := rf.GetRficRxFir()
+	val, _ := rf.GetRficRegister(0x037)
+	pre, sym, err := rf.GetRficRssi(Rx1Channel)
+
+	if err == nil && fir == RficRxFirDec2 && val == 0x5a && pre < 0 && sym < 0 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v %v 0x%x", err, fir, val)
+	}
+
+	if err = rf.SetTxMute(Rx1Channel, true); errors.Is(err, exception.Inval) {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err)
+	}
+}
+
+func TestSimulatorWaitPLLLock(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*simulatorPllLockTime)
+	defer cancel()
+
+	if err := rf.WaitPLLLock(ctx); err == context.DeadlineExceeded {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_ = rf.SetClockSelect(ClockSelectExternal)
+	_ = rf.SetPllRefClk(10000000)
+	_ = rf.SetPllEnable(true)
+	locked, _ := rf.GetPllLockState()
+	err := rf.WaitPLLLock(ctx)
+
+	if !locked && err == nil {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v %v", locked, err)
+	}
+
+	if err = rf.SetPllRefClk(1000); errors.Is(err, exception.Range) {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err)
+	}
+}
+
+func TestSimulatorPowerMonitor(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	reading, err := rf.ReadPower()
+
+	if err == nil && reading.Source == PsDc && reading.BusVoltage == 5.05 && reading.Current == 0.625 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v %+v", err, reading)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if _, err = NewPowerMonitor(rf, 0); errors.Is(err, exception.Inval) {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err)
+	}
+
+	monitor, _ := NewPowerMonitor(rf, time.Millisecond)
+	done := make(chan error)
+
+	go func() {
+		done <- monitor.Run(ctx)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	rf.mu.Lock()
+	rf.powerSource = PsUsbVbus
+	rf.mu.Unlock()
+
+	alert, ok := <-monitor.Alerts()
+	cancel()
+
+	if ok && alert.Previous == PsDc && alert.Reading.Source == PsUsbVbus && <-done == context.Canceled {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %+v", alert)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	monitor, _ = NewPowerMonitor(rf, time.Millisecond)
+
+	go func() {
+		done <- monitor.Run(ctx)
+	}()
+
+	for _, source := range []PowerSource{PsDc, PsUsbVbus, PsDc, PsUsbVbus} {
+		rf.mu.Lock()
+		rf.powerSource = source
+		rf.mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	latest := monitor.Latest()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	<-done
+
+	alerts := 0
+
+	for range monitor.Alerts() {
+		alerts++
+	}
+
+	if monitor.Latest().Time.After(latest.Time) && alerts == 1 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %d alerts", alerts)
+	}
+}
+
+func TestSimulatorCalibrateCorrections(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	rf.SetImpairments(Impairments{RxDcI: 40, RxDcQ: -25, TxLoI: -60, TxLoQ: 35, TxGain: 0.05, TxPhase: 3})
+	_ = rf.SyncConfig(TxX1, FormatSc16Q11, 2, 1024, 1, 3500)
+	_ = rf.SyncConfig(RxX1, FormatSc16Q11, 2, 1024, 1, 3500)
+
+	if _, err := rf.CalibrateCorrections(Rx1Channel, ChannelTx(0), []uint64{915000000}); errors.Is(err, exception.Inval) {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", err)
+	}
+
+	_ = rf.SyncConfig(TxX1, FormatSc16Q11Meta, 2, 1024, 1, 3500)
+	_ = rf.SyncConfig(RxX1, FormatSc16Q11Meta, 2, 1024, 1, 3500)
+
+	table, err := rf.CalibrateCorrections(Rx1Channel, ChannelTx(0), []uint64{915000000, 2400000000})
+
+	if err != nil || len(table.Channels[Rx1Channel]) != 2 || len(table.Channels[ChannelTx(0)]) != 2 ||
+		rf.GetCorrectionTable() != table {
+		t.Errorf("FAILED cause got %v", err)
+		return
+	}
+
+	loopback, _ := rf.GetLoopback()
+	frequency, _ := rf.GetFrequency(Rx1Channel)
+
+	if loopback != LoopbackDisabled || frequency != 2400000000 || rf.IsModuleEnabled(Rx1Channel) {
+		t.Errorf("FAILED cause got %v %v", loopback, frequency)
+	}
+
+	_ = rf.SetLoopback(LoopbackRficBist)
+	_ = rf.EnableModule(ChannelTx(0))
+	_ = rf.EnableModule(Rx1Channel)
+
+	buf := make([]int16, 2*(calibrationSettle+calibrationSamples))
+	leakage, _ := captureLoopback(rf, 1000, make([]int16, 2*(calibrationSettle+calibrationSamples)), buf)
+	leakagePower := dcPower(leakage)
+	image, _ := captureLoopback(rf, 1000, calibrationTone(), buf)
+
+	if len(image) == 2*calibrationSamples && leakagePower < 1 && imageRatio(image) < 1e-5 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v %v", leakagePower, imageRatio(image))
+	}
+
+	_ = rf.SetCorrection(ChannelTx(0), CorrectionDcoffI, 0)
+	_ = rf.SetFrequency(ChannelTx(0), 915000000)
+	low, _ := rf.GetCorrection(ChannelTx(0), CorrectionDcoffI)
+	_ = rf.SetFrequency(ChannelTx(0), 1657500000)
+	middle, _ := rf.GetCorrection(ChannelTx(0), CorrectionDcoffI)
+	expected, _ := table.Lookup(ChannelTx(0), 1657500000)
+
+	if low == table.Channels[ChannelTx(0)][0].Corrections[CorrectionDcoffI] && low != 0 &&
+		middle == expected[CorrectionDcoffI] {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v %v", low, middle)
+	}
+}
+
+func TestSimulatorHopper(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	_, _ = rf.SetSampleRate(Rx1Channel, 1000000)
+	_ = rf.SyncConfig(RxX1, FormatSc16Q11Meta, 16, 1024, 8, 3500)
+	_ = rf.EnableModule(Rx1Channel)
+
+	frequencies := []uint64{915000000, 920000000, 925000000}
+	hopper, err := NewHopper(rf, Rx1Channel, frequencies, time.Millisecond)
+	frequency, _ := rf.GetFrequency(Rx1Channel)
+
+	if err != nil || hopper.Dwell != 1000 || frequency != 2400000000 {
+		t.Errorf("FAILED cause got %v", err)
+		return
+	}
+
+	for i := 0; i < RetuneQueueDepth/2; i++ {
+		_ = rf.ScheduleReTune(ChannelTx(0), Timestamp(1000*i+500), 1000000000, QuickTune{})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+
+	go func() {
+		result <- hopper.Run(ctx, 1000)
+	}()
+
+	var received []Hop
+
+	for buffer := 0; buffer < 40; buffer++ {
+		deadline := time.Now().Add(2 * time.Second)
+
+		for {
+			hops := hopper.Hops()
+
+			if len(hops) > 0 && hops[len(hops)-1].Timestamp >= Timestamp(buffer*1000+2000) || time.Now().After(deadline) {
+				break
+			}
+
+			time.Sleep(time.Millisecond)
+		}
+
+		_, metadata, err := rf.SyncRX(1000, NewMetadata(0, MetaFlagRxNow), 3500)
+
+		if err != nil {
+			t.Errorf("FAILED cause got %v", err)
+			break
+		}
+
+		received = append(received, hopper.HopsIn(metadata)...)
+	}
+
+	cancel()
+	err = <-result
+	frequency, _ = rf.GetFrequency(Rx1Channel)
+	clock, _ := rf.GetTimestamp(Rx)
+	hops := hopper.Hops()
+
+	if err != context.Canceled || len(received) != 39 || len(rf.retunes) != 0 ||
+		hops[len(hops)-1].Timestamp != clock || frequency != hops[len(hops)-1].Frequency {
+		t.Errorf("FAILED cause got %v %d %d", err, len(received), frequency)
+		return
+	}
+
+	for i, hop := range received {
+		if hop.Index != i%3 || hop.Frequency != frequencies[i%3] ||
+			hop.Timestamp != Timestamp(1000*(i+1)) {
+			t.Errorf("FAILED cause got %v", hop)
+			return
+		}
+	}
+
+	t.Log("PASSED")
+}
+
+func TestSimulatorHopperLateStart(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	_, _ = rf.SetSampleRate(Rx1Channel, 1000000)
+	_ = rf.SyncConfig(RxX1, FormatSc16Q11Meta, 16, 1024, 8, 3500)
+	_ = rf.EnableModule(Rx1Channel)
+
+	hopper, err := NewHopper(rf, Rx1Channel, []uint64{915000000, 920000000, 925000000}, time.Millisecond)
+
+	if err != nil {
+		t.Errorf("FAILED cause got %v", err)
+		return
+	}
+
+	_, _, _ = rf.SyncRX(5500, NewMetadata(0, MetaFlagRxNow), 3500)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = hopper.Run(ctx, 1000)
+	hops := hopper.Hops()
+
+	if err == context.DeadlineExceeded && len(hops) == 0 && len(rf.retunes) == 0 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v %v", err, hops)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	result := make(chan error)
+
+	go func() {
+		result <- hopper.Run(ctx, 1000)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+
+	for len(hopper.Hops()) < RetuneQueueDepth && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	hops = hopper.Hops()
+	cancel()
+
+	select {
+	case err = <-result:
+	case <-time.After(2 * time.Second):
+		t.Error("FAILED cause Run did not return")
+		return
+	}
+
+	if err == context.Canceled && len(hops) == RetuneQueueDepth && hops[0].Timestamp == 6000 && hops[0].Index == 2 {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v %v", err, hops)
+	}
+}
+
+// clockDevice counts samples against a fake host clock, 20 ppm fast.
+type clockDevice struct {
+	*Simulator
+	now    time.Time
+	anchor time.Time
+	base   Timestamp
+	rate   float64
+}
+
+func (device *clockDevice) GetTimestamp(direction Direction) (Timestamp, error) {
+	samples := device.now.Sub(device.anchor).Seconds() * device.rate * (1 + 20e-6)
+	return device.base + Timestamp(samples+0.5), nil
+}
+
+func TestSimulatorClock(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	device := &clockDevice{Simulator: NewSimulator(), now: start, anchor: start, base: 5000, rate: 1000000}
+	defer device.Close()
+
+	_, _ = device.SetRationalSampleRate(Rx1Channel, RationalRate{Integer: 1000000, Num: 1, Den: 3})
+	clock, err := NewClock(device, Rx1Channel)
+
+	if err != nil || clock.FromDuration(3*time.Second) != 3000001 || clock.ToDuration(3000001) != 3*time.Second ||
+		clock.Add(1000, time.Millisecond) != 2000 || clock.Sub(3000001, 0) != 3*time.Second {
+		t.Errorf("FAILED cause got %v", err)
+		return
+	}
+
+	_, _ = device.SetRationalSampleRate(Rx1Channel, RationalRate{Integer: 1000000, Num: 0, Den: 1})
+	clock, _ = NewClock(device, Rx1Channel)
+	clock.now = func() time.Time { return device.now }
+	clock.observations = nil
+
+	for i := 0; i < 10; i++ {
+		device.now = device.now.Add(time.Second)
+		_ = clock.Sync()
+	}
+
+	timestamp, _ := device.GetTimestamp(Rx)
+	drift := clock.Drift()
+
+	if drift < 19e-6 || drift > 21e-6 || clock.ToTime(timestamp).Sub(device.now).Round(time.Microsecond) != 0 ||
+		clock.FromTime(device.now) != timestamp {
+		t.Errorf("FAILED cause got %v %v", drift, clock.ToTime(timestamp).Sub(device.now))
+	}
+
+	device.base, device.anchor, device.rate = timestamp, device.now, 2000000
+	_, _ = device.SetSampleRate(Rx1Channel, 2000000)
+	_ = clock.UpdateRate()
+	device.now = device.now.Add(2 * time.Second)
+	timestamp, _ = device.GetTimestamp(Rx)
+
+	if clock.Rate().Integer == 2000000 && clock.ToTime(timestamp).Sub(device.now).Round(time.Microsecond) == 0 &&
+		clock.Sub(timestamp, device.base).Round(time.Millisecond) == 2*time.Second {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v", clock.ToTime(timestamp).Sub(device.now))
+	}
+}
+
+type burstRecorder struct {
+	*Simulator
+	metadata []Metadata
+	lengths  []int
+}
+
+func (recorder *burstRecorder) SyncTX(input []int16, metadata Metadata, timeout uint) (Metadata, error) {
+	recorder.metadata = append(recorder.metadata, metadata)
+	recorder.lengths = append(recorder.lengths, len(input)/2)
+	return recorder.Simulator.SyncTX(input, metadata, timeout)
+}
+
+func TestSimulatorTransmitBurst(t *testing.T) {
+	rf := NewSimulator()
+	defer rf.Close()
+
+	_, _ = rf.SetSampleRate(ChannelTx(0), 1000000)
+	_ = rf.SyncConfig(TxX1, FormatSc16Q11Meta, 16, 1024, 8, 3500)
+	_ = rf.EnableModule(ChannelTx(0))
+
+	recorder := &burstRecorder{Simulator: rf}
+	samples := make([]int16, 2*2500)
+
+	for i := range samples {
+		samples[i] = 100
+	}
+
+	err := transmitBurst(context.Background(), recorder, FormatSc16Q11Meta, 1024, samples, 5000)
+	clock, _ := rf.GetTimestamp(Tx)
+
+	if err != nil || len(recorder.metadata) != 3 || clock != 5000+3*1024 ||
+		recorder.metadata[0].Flags != MetaFlagTxBurstStart || recorder.metadata[0].Timestamp != 5000 ||
+		recorder.metadata[1].Flags != 0 || recorder.metadata[2].Flags != MetaFlagTxBurstEnd || recorder.lengths[2] != 1024 {
+		t.Errorf("FAILED cause got %v %v", err, recorder.metadata)
+		return
+	}
+
+	chunks, _ := burstChunks(samples[:2*2048], 1024)
+	tail := chunks[len(chunks)-1]
+	empty, _ := burstChunks(nil, 1024)
+
+	if len(chunks) != 3 || tail[0] != 0 || len(empty) != 1 {
+		t.Errorf("FAILED cause got %d chunks", len(chunks))
+	}
+
+	samples[2*2048-2], samples[2*2048-1] = 0, 0
+
+	if chunks, _ = burstChunks(samples[:2*2048], 1024); len(chunks) != 2 {
+		t.Errorf("FAILED cause got %d chunks", len(chunks))
+	}
+
+	if err = transmitBurst(context.Background(), recorder, FormatSc16Q11Meta, 1024, samples[:3], 20000); !errors.Is(err, exception.Inval) {
+		t.Errorf("FAILED cause got %v", err)
+	}
+
+	_, err = rf.TransmitBursts(context.Background(), []Burst{{Samples: samples[:2], At: 9000}})
+	var late *LateBurstError
+	sent, lateErr := rf.TransmitBursts(context.Background(), []Burst{{Samples: samples[:2], At: 11000}, {Samples: samples[:2], At: 1000}})
+
+	if err == nil && sent == 1 && errors.As(lateErr, &late) && errors.Is(lateErr, exception.TimePast) &&
+		late.Now == 12024 && late.Lateness == 11024*time.Microsecond {
+		t.Log("PASSED")
+	} else {
+		t.Errorf("FAILED cause got %v %v", err, lateErr)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err = rf.TransmitBurst(ctx, samples, 20000); err != context.Canceled {
+		t.Errorf("FAILED cause got %v", err)
+	}
+
+	_ = rf.SyncConfig(TxX1, FormatSc16Q11, 16, 1024, 8, 3500)
+
+	if err = rf.TransmitBurst(context.Background(), samples, 20000); !errors.Is(err, exception.Inval) {
+		t.Errorf("FAILED cause got %v", err)
+	}
+}
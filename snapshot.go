@@ -0,0 +1,301 @@
+package bladerf
+
+import (
+	"fmt"
+	"strings"
+)
+
+var snapshotCorrections = []Correction{CorrectionDcoffI, CorrectionDcoffQ, CorrectionPhase, CorrectionGain}
+var snapshotTriggerSignals = []TriggerSignal{TriggerSignalJ714, TriggerSignalJ511, TriggerSignalMiniExp1}
+
+type ChannelSnapshot struct {
+	Channel     Channel
+	Frequency   uint64
+	SampleRate  RationalRate
+	Bandwidth   uint
+	GainMode    GainMode
+	Gain        int
+	GainStages  map[string]int
+	Corrections map[Correction]int16
+	RfPort      string
+	Enabled     bool
+}
+
+type TriggerSnapshot struct {
+	Channel  Channel
+	Signal   TriggerSignal
+	Role     TriggerRole
+	Register uint8
+}
+
+// Snapshot is the restorable state of a device. Skipped lists the fields the
+// device refused to report, which Restore leaves untouched.
+type Snapshot struct {
+	Channels        []ChannelSnapshot
+	Loopback        Loopback
+	RxMux           RxMux
+	TuningMode      TuningMode
+	VctcxoTamerMode VctcxoTamerMode
+	Triggers        []TriggerSnapshot
+	Skipped         []string
+}
+
+type RestoreFailure struct {
+	Field string
+	Err   error
+}
+
+// RestoreError lists every field Restore could not apply.
+type RestoreError struct {
+	Failures []RestoreFailure
+}
+
+func (e *RestoreError) Error() string {
+	failures := make([]string, len(e.Failures))
+
+	for i, failure := range e.Failures {
+		failures[i] = failure.Field + ": " + failure.Err.Error()
+	}
+
+	return "restore failed for " + strings.Join(failures, ", ")
+}
+
+func (snapshot *Snapshot) skipped(field string) bool {
+	for _, skipped := range snapshot.Skipped {
+		if skipped == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (snapshot *Snapshot) check(field string, err error) bool {
+	if err != nil {
+		snapshot.Skipped = append(snapshot.Skipped, field)
+		return false
+	}
+
+	return true
+}
+
+func triggerRole(register uint8) TriggerRole {
+	if register&uint8(TriggerRegMaster) != 0 {
+		return TriggerRoleMaster
+	} else if register&uint8(TriggerRegArm) != 0 {
+		return TriggerRoleSlave
+	}
+
+	return TriggerRoleDisabled
+}
+
+func takeSnapshot(device Device) (Snapshot, error) {
+	var snapshot Snapshot
+	var err error
+
+	snapshot.Loopback, err = device.GetLoopback()
+	snapshot.check("loopback", err)
+	snapshot.RxMux, err = device.GetRxMux()
+	snapshot.check("rx_mux", err)
+	snapshot.TuningMode, err = device.GetTuningMode()
+	snapshot.check("tuning_mode", err)
+	snapshot.VctcxoTamerMode, err = device.GetVctcxoTamerMode()
+	snapshot.check("vctcxo_tamer_mode", err)
+
+	for _, channel := range []Channel{ChannelRx(0), ChannelRx(1), ChannelTx(0), ChannelTx(1)} {
+		frequency, err := device.GetFrequency(channel)
+
+		if err != nil {
+			continue
+		}
+
+		snapshot.Channels = append(snapshot.Channels, takeChannelSnapshot(device, channel, frequency, &snapshot))
+
+		for _, signal := range snapshotTriggerSignals {
+			register, err := device.ReadTrigger(channel, signal)
+
+			if err == nil {
+				snapshot.Triggers = append(snapshot.Triggers, TriggerSnapshot{
+					Channel:  channel,
+					Signal:   signal,
+					Role:     triggerRole(register),
+					Register: register,
+				})
+			}
+		}
+	}
+
+	if len(snapshot.Channels) == 0 {
+		_, err := device.GetFrequency(ChannelRx(0))
+		return snapshot, err
+	}
+
+	return snapshot, nil
+}
+
+func takeChannelSnapshot(device Device, channel Channel, frequency uint64, snapshot *Snapshot) ChannelSnapshot {
+	var err error
+
+	field := func(name string) string {
+		return fmt.Sprintf("%s.%s", channel, name)
+	}
+
+	channelSnapshot := ChannelSnapshot{
+		Channel:     channel,
+		Frequency:   frequency,
+		GainStages:  make(map[string]int),
+		Corrections: make(map[Correction]int16),
+		Enabled:     device.IsModuleEnabled(channel),
+	}
+
+	channelSnapshot.SampleRate, err = device.GetRationalSampleRate(channel)
+	snapshot.check(field("sample_rate"), err)
+	channelSnapshot.Bandwidth, err = device.GetBandwidth(channel)
+	snapshot.check(field("bandwidth"), err)
+	channelSnapshot.GainMode, err = device.GetGainMode(channel)
+	snapshot.check(field("gain_mode"), err)
+	channelSnapshot.Gain, err = device.GetGain(channel)
+	snapshot.check(field("gain"), err)
+	channelSnapshot.RfPort, err = device.GetRfPort(channel)
+	snapshot.check(field("rf_port"), err)
+
+	stages, err := device.GetGainStages(channel)
+
+	if snapshot.check(field("gain_stages"), err) {
+		for _, stage := range stages {
+			if gain, err := device.GetGainStage(channel, stage); snapshot.check(field("gain_stage."+stage), err) {
+				channelSnapshot.GainStages[stage] = gain
+			}
+		}
+	}
+
+	for _, correction := range snapshotCorrections {
+		value, err := device.GetCorrection(channel, correction)
+
+		if snapshot.check(fmt.Sprintf("%s.correction.%d", channel, correction), err) {
+			channelSnapshot.Corrections[correction] = value
+		}
+	}
+
+	return channelSnapshot
+}
+
+// restoreSnapshot applies device wide modes first, then the sample clock and
+// filters of every channel before tuning it, so the gain and correction
+// values land on the final configuration. Modules are enabled last.
+func restoreSnapshot(device Device, snapshot Snapshot) error {
+	restoreError := &RestoreError{}
+
+	apply := func(field string, err error) {
+		if err != nil {
+			restoreError.Failures = append(restoreError.Failures, RestoreFailure{Field: field, Err: err})
+		}
+	}
+
+	for _, channel := range snapshot.Channels {
+		if !channel.Enabled {
+			apply(fmt.Sprintf("%s.enabled", channel.Channel), device.DisableModule(channel.Channel))
+		}
+	}
+
+	if !snapshot.skipped("tuning_mode") {
+		apply("tuning_mode", device.SetTuningMode(snapshot.TuningMode))
+	}
+
+	if !snapshot.skipped("vctcxo_tamer_mode") {
+		apply("vctcxo_tamer_mode", device.SetVctcxoTamerMode(snapshot.VctcxoTamerMode))
+	}
+
+	if !snapshot.skipped("rx_mux") {
+		apply("rx_mux", device.SetRxMux(snapshot.RxMux))
+	}
+
+	if !snapshot.skipped("loopback") {
+		apply("loopback", device.SetLoopback(snapshot.Loopback))
+	}
+
+	for _, channel := range snapshot.Channels {
+		restoreChannel(device, channel, snapshot, apply)
+	}
+
+	for _, trigger := range snapshot.Triggers {
+		field := fmt.Sprintf("%s.trigger.%d", trigger.Channel, trigger.Signal)
+		apply(field, device.WriteTrigger(trigger.Channel, trigger.Signal, trigger.Register&^uint8(TriggerRegFire)))
+	}
+
+	for _, channel := range snapshot.Channels {
+		if channel.Enabled {
+			apply(fmt.Sprintf("%s.enabled", channel.Channel), device.EnableModule(channel.Channel))
+		}
+	}
+
+	if len(restoreError.Failures) > 0 {
+		return restoreError
+	}
+
+	return nil
+}
+
+func restoreChannel(device Device, channel ChannelSnapshot, snapshot Snapshot, apply func(string, error)) {
+	field := func(name string) string {
+		return fmt.Sprintf("%s.%s", channel.Channel, name)
+	}
+
+	if !snapshot.skipped(field("sample_rate")) {
+		_, err := device.SetRationalSampleRate(channel.Channel, channel.SampleRate)
+		apply(field("sample_rate"), err)
+	}
+
+	if !snapshot.skipped(field("bandwidth")) {
+		_, err := device.SetBandwidth(channel.Channel, channel.Bandwidth)
+		apply(field("bandwidth"), err)
+	}
+
+	apply(field("frequency"), device.SetFrequency(channel.Channel, channel.Frequency))
+
+	if !snapshot.skipped(field("rf_port")) {
+		apply(field("rf_port"), device.SetRfPort(channel.Channel, channel.RfPort))
+	}
+
+	if !ChannelIsTx(int(channel.Channel)) && !snapshot.skipped(field("gain_mode")) {
+		apply(field("gain_mode"), device.SetGainMode(channel.Channel, channel.GainMode))
+	}
+
+	automatic := channel.GainMode == GainModeFastAttackAgc ||
+		channel.GainMode == GainModeSlowAttackAgc ||
+		channel.GainMode == GainModeHybridAgc
+
+	if !automatic {
+		if len(channel.GainStages) > 0 {
+			for stage, gain := range channel.GainStages {
+				apply(field("gain_stage."+stage), device.SetGainStage(channel.Channel, stage, gain))
+			}
+		} else if !snapshot.skipped(field("gain")) {
+			apply(field("gain"), device.SetGain(channel.Channel, channel.Gain))
+		}
+	}
+
+	for correction, value := range channel.Corrections {
+		apply(fmt.Sprintf("%s.correction.%d", channel.Channel, correction), device.SetCorrection(channel.Channel, correction, value))
+	}
+}
+
+// Snapshot captures the RF configuration of every channel the board has,
+// together with the device wide modes, so it can be reapplied by Restore.
+func (bladeRF *BladeRF) Snapshot() (Snapshot, error) {
+	return takeSnapshot(bladeRF)
+}
+
+// Restore reapplies a Snapshot. It keeps going past individual failures and
+// returns a *RestoreError naming every field that could not be set.
+func (bladeRF *BladeRF) Restore(snapshot Snapshot) error {
+	return restoreSnapshot(bladeRF, snapshot)
+}
+
+func (simulator *Simulator) Snapshot() (Snapshot, error) {
+	return takeSnapshot(simulator)
+}
+
+func (simulator *Simulator) Restore(snapshot Snapshot) error {
+	return restoreSnapshot(simulator, snapshot)
+}
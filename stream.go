@@ -0,0 +1,118 @@
+package bladerf
+
+import (
+	"context"
+	exception "github.com/erayarslan/go-bladerf/error"
+)
+
+type rxCallback func(samples []int16, metadata Metadata) GoStream
+
+func (bladeRF *BladeRF) StartRX(ctx context.Context, config StreamConfig) (<-chan SampleBlock, error) {
+	if config.Format.SampleSize() != 4 {
+		return nil, exception.NewWithOperation(int(exception.Inval), "init_stream", formatArguments("format", config.Format))
+	}
+
+	userData := NewUserData(nil, config.SamplesPerBuffer)
+	stream, err := bladeRF.initStream(
+		config.Format,
+		config.NumBuffers,
+		config.SamplesPerBuffer,
+		config.NumTransfers,
+		&userData,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return receiveBlocks(ctx, config, func(callback rxCallback) error {
+		userData.rxCallback = callback
+		return stream.Start(config.Layout)
+	}, func() {
+		disableLayout(bladeRF, config.Layout)
+	}, stream.DeInit), nil
+}
+
+// disableLayout disables the RX channels of layout, which makes a running
+// stream return even when no buffers are arriving.
+func disableLayout(device Device, layout ChannelLayout) {
+	_ = device.DisableModule(ChannelRx(0))
+
+	if layout == RxX2 {
+		_ = device.DisableModule(ChannelRx(1))
+	}
+}
+
+// receiveBlocks runs start on its own goroutine and forwards every buffer
+// handed to the callback as a SampleBlock until ctx is cancelled or the
+// stream fails. Cancelling ctx calls stop, so a stalled stream ends without
+// waiting for the libbladeRF timeout; the RX channels are left disabled.
+// The stream is always torn down before the channel closes.
+func receiveBlocks(
+	ctx context.Context,
+	config StreamConfig,
+	start func(callback rxCallback) error,
+	stop func(),
+	deInit func(),
+) <-chan SampleBlock {
+	blocks := make(chan SampleBlock, config.NumBuffers)
+	timestamp := Timestamp(0)
+	overrun := false
+
+	callback := func(samples []int16, metadata Metadata) GoStream {
+		if ctx.Err() != nil {
+			return GoStreamShutdown
+		}
+
+		block := SampleBlock{
+			Samples:   make([]int16, len(samples)),
+			Timestamp: timestamp,
+			Status:    metadata.Status,
+			Overrun:   overrun || metadata.Status&MetaStatusOverrun != 0,
+		}
+
+		copy(block.Samples, samples)
+
+		if metadata.Timestamp != 0 {
+			block.Timestamp = metadata.Timestamp
+		}
+
+		timestamp = block.Timestamp + Timestamp(len(samples)/2)
+
+		select {
+		case blocks <- block:
+			overrun = false
+		default:
+			overrun = true
+		}
+
+		return GoStreamNext
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			stop()
+		case <-done:
+		}
+	}()
+
+	go func() {
+		defer close(blocks)
+		defer deInit()
+		defer close(done)
+
+		err := start(callback)
+
+		if err != nil && ctx.Err() == nil {
+			select {
+			case blocks <- SampleBlock{Err: err}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	return blocks
+}
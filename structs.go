@@ -2,6 +2,10 @@ package bladerf
 
 // #include <libbladeRF.h>
 import "C"
+import (
+	"sync"
+	"unsafe"
+)
 
 type Timestamp uint64
 
@@ -95,7 +99,21 @@ func NewRange(ref *C.struct_bladerf_range) Range {
 }
 
 type BladeRF struct {
-	ref *C.struct_bladerf
+	ref             *C.struct_bladerf
+	syncFormats     map[Direction]Format
+	syncBufferSizes map[Direction]uint
+	enabledModules  map[Channel]bool
+	expansionBoard  ExpansionBoard
+	correctionTable *CorrectionTable
+}
+
+func newBladeRF(ref *C.struct_bladerf) BladeRF {
+	return BladeRF{
+		ref:             ref,
+		syncFormats:     make(map[Direction]Format),
+		syncBufferSizes: make(map[Direction]uint),
+		enabledModules:  make(map[Channel]bool),
+	}
 }
 
 type QuickTune struct {
@@ -119,7 +137,30 @@ func NewSerial(ref *C.struct_bladerf_serial) Serial {
 }
 
 type Stream struct {
-	ref *C.struct_bladerf_stream
+	ref       *C.struct_bladerf_stream
+	userData  *UserData
+	handle    unsafe.Pointer
+	simulator *simulatorStream
+}
+
+type StreamConfig struct {
+	Layout           ChannelLayout
+	Format           Format
+	NumBuffers       int
+	SamplesPerBuffer int
+	NumTransfers     int
+}
+
+// SampleBlock is one buffer of interleaved samples delivered by StartRX.
+// Overrun is set when libbladeRF reported an overrun or when blocks had to
+// be dropped because the receiver did not keep up. A block with a non-nil
+// Err is the last one sent before the channel is closed.
+type SampleBlock struct {
+	Samples   []int16
+	Timestamp Timestamp
+	Status    uint32
+	Overrun   bool
+	Err       error
 }
 
 type Trigger struct {
@@ -186,10 +227,78 @@ func NewMetadata(timestamp Timestamp, flags uint32) Metadata {
 
 type UserData struct {
 	callback   func(data []int16) GoStream
+	callback8  func(data []int8) GoStream
+	rxCallback func(samples []int16, metadata Metadata) GoStream
 	results    []int16
+	results8   []int8
 	bufferSize int
+	buffers    []unsafe.Pointer
+	next       int
+	direction  Direction
 }
 
 func NewUserData(callback func(data []int16) GoStream, bufferSize int) UserData {
-	return UserData{callback: callback, results: make([]int16, bufferSize), bufferSize: bufferSize}
+	return UserData{callback: callback, results: make([]int16, bufferSize*2), bufferSize: bufferSize}
+}
+
+func (userData *UserData) nextBuffer() unsafe.Pointer {
+	buffer := userData.buffers[userData.next]
+	userData.next = (userData.next + 1) % len(userData.buffers)
+	return buffer
+}
+
+func (userData *UserData) buffer(buffer unsafe.Pointer) []int16 {
+	return (*[1 << 30]int16)(buffer)[: userData.bufferSize*2 : userData.bufferSize*2]
+}
+
+func (userData *UserData) buffer8(buffer unsafe.Pointer) []int8 {
+	return (*[1 << 30]int8)(buffer)[: userData.bufferSize*2 : userData.bufferSize*2]
+}
+
+// SampleBuffer holds interleaved samples in C memory, so it can be handed to
+// libbladeRF repeatedly without allocation. It must be released with Free.
+type SampleBuffer struct {
+	ref     unsafe.Pointer
+	Samples []int16
+}
+
+func NewSampleBuffer(numSamples int) SampleBuffer {
+	ref := C.malloc(C.size_t(C.sizeof_int16_t * uintptr(numSamples) * 2))
+	return SampleBuffer{ref: ref, Samples: (*[1 << 30]int16)(ref)[: numSamples*2 : numSamples*2]}
+}
+
+func (buffer *SampleBuffer) Free() {
+	C.free(buffer.ref)
+	buffer.ref = nil
+	buffer.Samples = nil
+}
+
+// SampleBufferPool recycles fixed size sample slices between SyncRXInto and
+// SyncTXFrom calls to keep the garbage collector out of the streaming path.
+type SampleBufferPool struct {
+	pool       sync.Pool
+	numSamples int
+}
+
+func NewSampleBufferPool(numSamples int) *SampleBufferPool {
+	bufferPool := &SampleBufferPool{numSamples: numSamples}
+	bufferPool.pool.New = func() interface{} {
+		buf := make([]int16, numSamples*2)
+		return &buf
+	}
+
+	return bufferPool
+}
+
+func (bufferPool *SampleBufferPool) Get() []int16 {
+	return *bufferPool.pool.Get().(*[]int16)
+}
+
+func (bufferPool *SampleBufferPool) Put(buf []int16) {
+	if cap(buf) < bufferPool.numSamples*2 {
+		return
+	}
+
+	buf = buf[:bufferPool.numSamples*2]
+	bufferPool.pool.Put(&buf)
 }
@@ -0,0 +1,74 @@
+package bladerf
+
+// XB200MixMaxFrequency is the frequency below which the XB-200 mixer path
+// is used. Above it the LMS6002D tunes the signal directly.
+const XB200MixMaxFrequency = 300000000
+
+type xb200Band struct {
+	filter XB200Filter
+	min    uint64
+	max    uint64
+}
+
+// Passbands of the XB-200 filter banks at their 1 dB and 3 dB points, as
+// used by libbladeRF's automatic filter selection.
+var xb200Bands = map[XB200Filter][]xb200Band{
+	XB200FilterAuto1dB: {
+		{XB200Filter50M, 37774405, 59535436},
+		{XB200Filter144M, 128326173, 166711171},
+		{XB200Filter222M, 187593160, 245346403},
+	},
+	XB200FilterAuto3dB: {
+		{XB200Filter50M, 34782924, 61899260},
+		{XB200Filter144M, 121956957, 178444099},
+		{XB200Filter222M, 177522675, 260140935},
+	},
+}
+
+// XB200Select returns the path and filter bank for frequency. Tolerance is
+// XB200FilterAuto1dB or XB200FilterAuto3dB and picks how far past its band
+// edge a filter may still be used; frequencies outside every band get the
+// custom filter path. Above XB200MixMaxFrequency the board is bypassed.
+func XB200Select(frequency uint64, tolerance XB200Filter) (XB200Path, XB200Filter) {
+	if frequency >= XB200MixMaxFrequency {
+		return XB200PathBypass, tolerance
+	}
+
+	bands, ok := xb200Bands[tolerance]
+
+	if !ok {
+		bands = xb200Bands[XB200FilterAuto1dB]
+	}
+
+	for _, band := range bands {
+		if frequency >= band.min && frequency <= band.max {
+			return XB200PathMix, band.filter
+		}
+	}
+
+	return XB200PathMix, XB200FilterCustom
+}
+
+// selectXB200 only switches the path. The filter bank is left as it is, so
+// an explicit bank is kept and the automatic modes are still resolved by
+// libbladeRF when it tunes.
+func selectXB200(device Device, channel Channel, frequency uint64) error {
+	path, _ := XB200Select(frequency, XB200FilterAuto1dB)
+	return device.XB200SetPath(channel, path)
+}
+
+// tuneFrequency wraps tune with the XB-200 path selection and the correction
+// table lookup done around every frequency change.
+func tuneFrequency(device Device, expansionBoard ExpansionBoard, table *CorrectionTable, channel Channel, frequency uint64, tune func() error) error {
+	if expansionBoard == ExpansionBoard200 {
+		if err := selectXB200(device, channel, frequency); err != nil {
+			return err
+		}
+	}
+
+	if err := tune(); err != nil || table == nil {
+		return err
+	}
+
+	return table.Apply(device, channel, frequency)
+}
@@ -0,0 +1,44 @@
+package bladerf
+
+// xb300SwitchTo turns the board around for half-duplex use. The active
+// module and its amplifier are shut off before the TRX switch moves, so the
+// PA never drives the LNA, and the new side is only powered once the switch
+// points at it.
+func xb300SwitchTo(device Device, direction Direction) error {
+	steps := []func() error{
+		func() error { return device.DisableModule(ChannelTx(0)) },
+		func() error { return device.XB300SetAmplifierEnable(XB300AmplifierPa, false) },
+		func() error { return device.XB300SetAmplifierEnable(XB300AmplifierPaAux, false) },
+		func() error { return device.XB300SetTrx(XB300TrxRx) },
+		func() error { return device.XB300SetAmplifierEnable(XB300AmplifierLna, true) },
+		func() error { return device.EnableModule(ChannelRx(0)) },
+	}
+
+	if direction == Tx {
+		steps = []func() error{
+			func() error { return device.DisableModule(ChannelRx(0)) },
+			func() error { return device.XB300SetAmplifierEnable(XB300AmplifierLna, false) },
+			func() error { return device.XB300SetTrx(XB300TrxTx) },
+			func() error { return device.XB300SetAmplifierEnable(XB300AmplifierPa, true) },
+			func() error { return device.EnableModule(ChannelTx(0)) },
+		}
+	}
+
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// XB300SwitchTo sets up the XB-300 and the RF modules for transmitting or
+// receiving through the shared TRX port, leaving the other direction off.
+func (bladeRF *BladeRF) XB300SwitchTo(direction Direction) error {
+	return xb300SwitchTo(bladeRF, direction)
+}
+
+func (simulator *Simulator) XB300SwitchTo(direction Direction) error {
+	return xb300SwitchTo(simulator, direction)
+}